@@ -0,0 +1,190 @@
+// Package diagram renders workflows as Graphviz DOT or Mermaid flowchart diagrams. A diagram can be created
+// from a wf.Step tree or from the definitions returned by the Metadata method of a service.
+package diagram
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// A Diagram is a renderable representation of one or several workflow steps
+type Diagram interface {
+	// DOT writes the diagram in Graphviz DOT format to the given writer
+	DOT(w io.Writer) error
+
+	// Mermaid writes the diagram in Mermaid flowchart format to the given writer
+	Mermaid(w io.Writer) error
+}
+
+// node is a step in the diagram. Nodes with the style workflow, iterator, guard, or switch are containers
+type node struct {
+	id         string
+	name       string
+	style      string
+	when       string
+	detail     string
+	parameters []string
+	returns    []string
+	children   []*node
+}
+
+// edge is a data-flow edge between two nodes in the same container. The label is the name
+// of the value that flows along the edge
+type edge struct {
+	from  *node
+	to    *node
+	label string
+}
+
+type diagram struct {
+	name  string
+	roots []*node
+}
+
+// FromStep creates a Diagram from the given step and its nested steps
+func FromStep(step wf.Step) Diagram {
+	d := &diagram{name: step.Name()}
+	d.roots = []*node{d.fromStep(step)}
+	return d
+}
+
+// FromDefinitions creates a Diagram from the given step definitions. Definitions that
+// don't describe a step, such as those of callables, are ignored.
+func FromDefinitions(c px.Context, name string, definitions []serviceapi.Definition) Diagram {
+	d := &diagram{name: name, roots: make([]*node, 0, len(definitions))}
+	for _, def := range definitions {
+		if service.IsStepDefinition(def) {
+			// The reconstructed step is never executed so its proxies need no service
+			d.roots = append(d.roots, d.fromStep(service.StepFromDefinition(c, nil, def)))
+		}
+	}
+	return d
+}
+
+func (d *diagram) fromStep(step wf.Step) *node {
+	n := &node{name: step.Name()}
+	if step.When() != wf.Always {
+		n.when = step.When().String()
+	}
+	for _, p := range wf.StepParameters(step) {
+		n.parameters = append(n.parameters, p.Name())
+	}
+	n.returns = wf.ReturnedNames(step)
+
+	switch step := step.(type) {
+	case wf.Workflow:
+		n.style = `workflow`
+		for _, s := range step.Steps() {
+			n.children = append(n.children, d.fromStep(s))
+		}
+	case wf.Iterator:
+		n.style = `iterator`
+		n.detail = step.IterationStyle().String()
+		n.children = []*node{d.fromStep(step.Producer())}
+	case wf.Guard:
+		n.style = `guard`
+		n.children = []*node{d.fromStep(step.Body()), d.fromStep(step.Handler())}
+	case wf.Switch:
+		n.style = `switch`
+		for _, cs := range step.Cases() {
+			n.children = append(n.children, caseNode(d.fromStep(cs.Step), cs.Condition))
+		}
+		if ds := step.Default(); ds != nil {
+			n.children = append(n.children, d.fromStep(ds))
+		}
+	case wf.Resource:
+		n.style = `resource`
+		if st := step.State(); st != nil && st.Type() != nil {
+			n.detail = st.Type().Name()
+		}
+	case wf.StateHandler:
+		n.style = `stateHandler`
+	case wf.Action:
+		n.style = `action`
+	case wf.Wait:
+		n.style = `wait`
+		n.detail = `until ` + step.Until().String()
+	case wf.Call:
+		n.style = `call`
+		n.detail = step.Call()
+	default:
+		n.style = `step`
+	}
+	return n
+}
+
+// caseNode shows the condition of a case of a switch as the when condition of the node of its step
+func caseNode(n *node, cond wf.Condition) *node {
+	if n.when == `` {
+		n.when = cond.String()
+	} else {
+		n.when = wf.And([]wf.Condition{cond, wf.Parse(n.when)}).String()
+	}
+	return n
+}
+
+// assignIds assigns a unique id to each node in depth first order
+func (d *diagram) assignIds() {
+	cnt := 0
+	var assign func(ns []*node)
+	assign = func(ns []*node) {
+		for _, n := range ns {
+			n.id = `n` + strconv.Itoa(cnt)
+			cnt++
+			assign(n.children)
+		}
+	}
+	assign(d.roots)
+}
+
+// label returns the text shown for the given node. Nodes that are nested in a container are
+// labeled with their leaf name.
+func (n *node) label(nested bool) string {
+	name := n.name
+	if nested {
+		name = wf.LeafName(name)
+	}
+	l := n.style + ` ` + name
+	if n.detail != `` {
+		if n.style == `call` {
+			l += "\ncalls " + n.detail
+		} else {
+			l += "\n" + n.detail
+		}
+	}
+	if n.when != `` {
+		l += "\nwhen " + n.when
+	}
+	return l
+}
+
+func (n *node) isContainer() bool {
+	return n.style == `workflow` || n.style == `iterator` || n.style == `guard` || n.style == `switch`
+}
+
+// edges returns the data-flow edges between the children of the given nodes. A child
+// parameter is connected to the child that returns a value with the same name.
+func edges(children []*node) []edge {
+	returnedBy := make(map[string]*node)
+	for _, c := range children {
+		for _, r := range c.returns {
+			if _, found := returnedBy[r]; !found {
+				returnedBy[r] = c
+			}
+		}
+	}
+	es := make([]edge, 0)
+	for _, c := range children {
+		for _, p := range c.parameters {
+			if from, ok := returnedBy[p]; ok && from != c {
+				es = append(es, edge{from, c, p})
+			}
+		}
+	}
+	return es
+}
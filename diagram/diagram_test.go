@@ -0,0 +1,112 @@
+package diagram_test
+
+import (
+	"os"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/diagram"
+	"github.com/lyraproj/servicesdk/lang/go/lyra"
+	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+func testWorkflow(c px.Context) wf.Workflow {
+	return wf.NewWorkflow(c, func(b wf.WorkflowBuilder) {
+		b.Name(`test`)
+		b.Parameters(b.Parameter(`region`, `String`))
+		b.Action(func(ab wf.ActionBuilder) {
+			ab.Name(`vpc`)
+			ab.Parameters(ab.Parameter(`region`, `String`))
+			ab.Returns(ab.Parameter(`vpcId`, `String`))
+		})
+		b.Iterator(func(ib wf.IteratorBuilder) {
+			ib.Name(`subnets`)
+			ib.Style(wf.IterationStyleTimes)
+			ib.Parameters(ib.Parameter(`vpcId`, `String`))
+			ib.Returns(ib.Parameter(`subnetIds`, `Array[String]`))
+			ib.Call(func(cb wf.CallBuilder) {
+				cb.Name(`subnet`)
+				cb.CallTo(`subnet`)
+			})
+		})
+		b.Action(func(ab wf.ActionBuilder) {
+			ab.Name(`instance`)
+			ab.When(`!dryRun`)
+			ab.Parameters(ab.Parameter(`vpcId`, `String`), ab.Parameter(`subnetIds`, `Array[String]`))
+		})
+	})
+}
+
+func ExampleDiagram_DOT() {
+	pcore.Do(func(c px.Context) {
+		_ = diagram.FromStep(testWorkflow(c)).DOT(os.Stdout)
+	})
+
+	// Output:
+	// digraph "test" {
+	//   compound=true;
+	//   subgraph "cluster_n0" {
+	//     label="workflow test";
+	//     n0 [shape=point, style=invis];
+	//     n1 [label="action vpc", shape=box];
+	//     subgraph "cluster_n2" {
+	//       label="iterator subnets\ntimes";
+	//       n2 [shape=point, style=invis];
+	//       n3 [label="call subnet\ncalls subnet", shape=cds];
+	//     }
+	//     n4 [label="action instance\nwhen !dryRun", shape=box];
+	//     n1 -> n2 [label="vpcId", lhead="cluster_n2"];
+	//     n1 -> n4 [label="vpcId"];
+	//     n2 -> n4 [label="subnetIds", ltail="cluster_n2"];
+	//   }
+	// }
+}
+
+func ExampleDiagram_Mermaid() {
+	pcore.Do(func(c px.Context) {
+		_ = diagram.FromStep(testWorkflow(c)).Mermaid(os.Stdout)
+	})
+
+	// Output:
+	// flowchart TD
+	//   subgraph n0 ["workflow test"]
+	//     n1["action vpc"]
+	//     subgraph n2 ["iterator subnets<br/>times"]
+	//       n3>"call subnet<br/>calls subnet"]
+	//     end
+	//     n4["action instance<br/>when !dryRun"]
+	//     n1 -->|"vpcId"| n2
+	//     n1 -->|"vpcId"| n4
+	//     n2 -->|"subnetIds"| n4
+	//   end
+}
+
+func ExampleFromDefinitions() {
+	type Out struct {
+		Answer int
+	}
+
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Workflow{
+			Steps: map[string]lyra.Step{
+				`ask`: &lyra.Action{
+					Do: func() Out { return Out{42} }},
+				`tell`: &lyra.Action{
+					When: `answer`,
+					Do:   func(in struct{ Answer int }) {}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		_, defs := sb.Server().Metadata(c)
+		_ = diagram.FromDefinitions(c, `My::Service`, defs).Mermaid(os.Stdout)
+	})
+
+	// Output:
+	// flowchart TD
+	//   subgraph n0 ["workflow My::Test"]
+	//     n1["action ask"]
+	//     n2["action tell<br/>when answer"]
+	//     n1 -->|"answer"| n2
+	//   end
+}
@@ -0,0 +1,97 @@
+package diagram
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+var dotShapes = map[string]string{
+	`action`:       `box`,
+	`resource`:     `cylinder`,
+	`stateHandler`: `component`,
+	`call`:         `cds`,
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (d *diagram) DOT(w io.Writer) error {
+	d.assignIds()
+	b := bytes.NewBufferString(``)
+	b.WriteString(`digraph "`)
+	b.WriteString(dotEscaper.Replace(d.name))
+	b.WriteString("\" {\n  compound=true;\n")
+	for _, n := range d.roots {
+		dotNode(b, n, false, 1)
+	}
+	dotEdges(b, d.roots, 1)
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func dotNode(b *bytes.Buffer, n *node, nested bool, indent int) {
+	if n.isContainer() {
+		// A cluster cannot be the endpoint of an edge. An invisible point node is added to the
+		// cluster and used as the endpoint together with ltail or lhead.
+		writeIndent(b, indent)
+		b.WriteString(`subgraph "cluster_`)
+		b.WriteString(n.id)
+		b.WriteString("\" {\n")
+		writeIndent(b, indent+1)
+		b.WriteString(`label="`)
+		b.WriteString(dotEscaper.Replace(n.label(nested)))
+		b.WriteString("\";\n")
+		writeIndent(b, indent+1)
+		b.WriteString(n.id)
+		b.WriteString(" [shape=point, style=invis];\n")
+		for _, c := range n.children {
+			dotNode(b, c, true, indent+1)
+		}
+		dotEdges(b, n.children, indent+1)
+		writeIndent(b, indent)
+		b.WriteString("}\n")
+		return
+	}
+
+	shape, ok := dotShapes[n.style]
+	if !ok {
+		shape = `ellipse`
+	}
+	writeIndent(b, indent)
+	b.WriteString(n.id)
+	b.WriteString(` [label="`)
+	b.WriteString(dotEscaper.Replace(n.label(nested)))
+	b.WriteString(`", shape=`)
+	b.WriteString(shape)
+	b.WriteString("];\n")
+}
+
+func dotEdges(b *bytes.Buffer, children []*node, indent int) {
+	for _, e := range edges(children) {
+		writeIndent(b, indent)
+		b.WriteString(e.from.id)
+		b.WriteString(` -> `)
+		b.WriteString(e.to.id)
+		b.WriteString(` [label="`)
+		b.WriteString(dotEscaper.Replace(e.label))
+		b.WriteByte('"')
+		if e.from.isContainer() {
+			b.WriteString(`, ltail="cluster_`)
+			b.WriteString(e.from.id)
+			b.WriteByte('"')
+		}
+		if e.to.isContainer() {
+			b.WriteString(`, lhead="cluster_`)
+			b.WriteString(e.to.id)
+			b.WriteByte('"')
+		}
+		b.WriteString("];\n")
+	}
+}
+
+func writeIndent(b *bytes.Buffer, indent int) {
+	for i := 0; i < indent; i++ {
+		b.WriteString(`  `)
+	}
+}
@@ -0,0 +1,71 @@
+package diagram
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// mermaidShapes contains the opening and closing delimiters of the node shape for each style
+var mermaidShapes = map[string][2]string{
+	`action`:       {`[`, `]`},
+	`resource`:     {`[(`, `)]`},
+	`stateHandler`: {`[[`, `]]`},
+	`call`:         {`>`, `]`},
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, `#quot;`, "\n", `<br/>`)
+
+func (d *diagram) Mermaid(w io.Writer) error {
+	d.assignIds()
+	b := bytes.NewBufferString("flowchart TD\n")
+	for _, n := range d.roots {
+		mermaidNode(b, n, false, 1)
+	}
+	mermaidEdges(b, d.roots, 1)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func mermaidNode(b *bytes.Buffer, n *node, nested bool, indent int) {
+	if n.isContainer() {
+		writeIndent(b, indent)
+		b.WriteString(`subgraph `)
+		b.WriteString(n.id)
+		b.WriteString(` ["`)
+		b.WriteString(mermaidEscaper.Replace(n.label(nested)))
+		b.WriteString("\"]\n")
+		for _, c := range n.children {
+			mermaidNode(b, c, true, indent+1)
+		}
+		mermaidEdges(b, n.children, indent+1)
+		writeIndent(b, indent)
+		b.WriteString("end\n")
+		return
+	}
+
+	shape, ok := mermaidShapes[n.style]
+	if !ok {
+		shape = [2]string{`(`, `)`}
+	}
+	writeIndent(b, indent)
+	b.WriteString(n.id)
+	b.WriteString(shape[0])
+	b.WriteByte('"')
+	b.WriteString(mermaidEscaper.Replace(n.label(nested)))
+	b.WriteByte('"')
+	b.WriteString(shape[1])
+	b.WriteByte('\n')
+}
+
+func mermaidEdges(b *bytes.Buffer, children []*node, indent int) {
+	for _, e := range edges(children) {
+		writeIndent(b, indent)
+		b.WriteString(e.from.id)
+		b.WriteString(` -->|"`)
+		b.WriteString(mermaidEscaper.Replace(e.label))
+		b.WriteString(`"| `)
+		b.WriteString(e.to.id)
+		b.WriteByte('\n')
+	}
+}
@@ -0,0 +1,807 @@
+// Package executor provides an in-process workflow executor. It executes the workflow definitions that are
+// made available by one or several services without the need for an external Lyra engine and is primarily
+// intended for testing workflows end-to-end.
+package executor
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/identity"
+	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// Executor executes steps declared by the definitions of a set of services.
+//
+// An action is executed by invoking its `do` method. A resource is executed by resolving its state using
+// the State method of its service and then applying that state using the handler that has been registered
+// for the state type. The mapping between resources and external ids is maintained in a serviceapi.Identity.
+// A guard executes its body and, when the body fails, its handler. A switch executes the step of its first
+// case with a condition that is true, or its default step. A wait reads the state of a resource, or invokes
+// an API method, until its until condition holds.
+type Executor struct {
+	steps     map[string]*stepRef
+	handlers  map[string]*stepRef
+	resources map[string]*stepRef
+	identity  serviceapi.Identity
+}
+
+// stepRef is a definition together with the service that provides it
+type stepRef struct {
+	service    serviceapi.Service
+	definition serviceapi.Definition
+	step       wf.Step
+}
+
+// asStep returns the step that the definition describes. The step is reconstructed on first use.
+func (r *stepRef) asStep(c px.Context) wf.Step {
+	if r.step == nil {
+		r.step = service.StepFromDefinition(c, r.service, r.definition)
+	}
+	return r.step
+}
+
+// New creates an Executor for the steps and handlers defined by the given services. The given identity is
+// used for the mapping of resources to external ids. An in-memory identity is created when it is nil.
+func New(c px.Context, id serviceapi.Identity, services ...serviceapi.Service) *Executor {
+	if id == nil {
+		id = identity.NewMemory()
+	}
+	e := &Executor{steps: make(map[string]*stepRef), handlers: make(map[string]*stepRef), resources: make(map[string]*stepRef), identity: id}
+	for _, s := range services {
+		_, defs := s.Metadata(c)
+		for _, def := range defs {
+			ref := &stepRef{service: s, definition: def}
+			props := def.Properties()
+			if hf, ok := props.Get4(`handlerFor`); ok {
+				e.handlers[typeName(hf)] = ref
+			}
+			if service.StringProp(props, `style`) != `callable` {
+				e.steps[def.Identifier().Name()] = ref
+				e.indexResources(ref)
+			}
+		}
+	}
+	return e
+}
+
+// indexResources adds the given definition and its nested definitions to the resources map when they describe
+// a resource
+func (e *Executor) indexResources(ref *stepRef) {
+	props := ref.definition.Properties()
+	if service.StringProp(props, `style`) == `resource` {
+		e.resources[ref.definition.Identifier().Name()] = ref
+		return
+	}
+	props.EachValue(func(v px.Value) {
+		switch v := v.(type) {
+		case serviceapi.Definition:
+			e.indexResources(&stepRef{service: ref.service, definition: v})
+		case px.List:
+			v.Each(func(ev px.Value) {
+				if h, ok := ev.(px.OrderedMap); ok {
+					ev = h.Get5(`step`, px.Undef)
+				}
+				if d, ok := ev.(serviceapi.Definition); ok {
+					e.indexResources(&stepRef{service: ref.service, definition: d})
+				}
+			})
+		}
+	})
+}
+
+// Identity returns the identity that maps resources to external ids
+func (e *Executor) Identity() serviceapi.Identity {
+	return e.identity
+}
+
+// Run executes the step with the given name using the given parameters and returns the values returned by
+// the step. An error is raised if the step, or any of its nested steps, fails.
+func (e *Executor) Run(c px.Context, stepName string, parameters px.OrderedMap) px.OrderedMap {
+	ref, ok := e.steps[stepName]
+	if !ok {
+		panic(px.Error(NoSuchStep, issue.H{`name`: stepName}))
+	}
+	s := newScope()
+	parameters.EachPair(func(k, v px.Value) { s[k.String()] = v })
+	e.identity.BumpEra(c)
+	result, _ := e.execute(c, ref, s)
+	return result.hash()
+}
+
+// execute executes the given step using values from the given scope. The returned scope contains the values
+// returned by the step. The returned boolean is false when the step was skipped because its when condition
+// evaluated to false.
+func (e *Executor) execute(c px.Context, ref *stepRef, s scope) (scope, bool) {
+	def := ref.definition
+	props := def.Properties()
+
+	// The when condition is evaluated before the arguments are resolved since the parameters of a skipped step
+	// need not be resolvable
+	if when := wf.ToCondition(props.Get5(`when`, px.Undef)); when != wf.Always {
+		cs := s.copy()
+		for _, p := range service.ParamsProp(props, `parameters`) {
+			if _, ok := cs[p.Name()]; !ok && p.Value() != nil {
+				cs[p.Name()] = types.ResolveDeferred(c, p.Value(), s.hash())
+			}
+		}
+		if !when.IsTrue(cs.hash()) {
+			return newScope(), false
+		}
+	}
+	args := e.arguments(c, ref, s)
+
+	var result px.OrderedMap
+	switch style := service.StringProp(props, `style`); style {
+	case `action`:
+		result = e.invokeForHash(c, ref, retryPolicy(def), strings.Title(def.Identifier().Name()), `do`, args.hash())
+	case `resource`:
+		result = e.applyResource(c, ref, args)
+	case `workflow`:
+		result = e.runWorkflow(c, ref, args)
+	case `iterator`:
+		result = e.iterate(c, ref, args)
+	case `guard`:
+		result = e.guard(c, ref, args)
+	case `switch`:
+		result = e.selectCase(c, ref, args)
+	case `wait`:
+		result = e.wait(c, ref, args)
+	case `call`:
+		called, ok := e.steps[service.StringProp(props, `call`)]
+		if !ok {
+			panic(px.Error(NoSuchStep, issue.H{`name`: service.StringProp(props, `call`)}))
+		}
+		cr, _ := e.execute(c, called, args)
+		result = cr.hash()
+	case `stateHandler`:
+		result = px.EmptyMap
+	default:
+		panic(px.Error(UnknownStepStyle, issue.H{`step`: def.Label(), `style`: style}))
+	}
+
+	returns := newScope()
+	for _, r := range service.ParamsProp(props, `returns`) {
+		returns[r.Name()] = get(result, inner(r))
+	}
+	if len(returns) == 0 {
+		// No declared returns. Everything returned by the step is returned as is
+		result.EachPair(func(k, v px.Value) { returns[k.String()] = v })
+	}
+	return returns, true
+}
+
+// arguments resolves the parameters of the given definition from the given scope. The names of the returned
+// arguments are the names used inside of the step, i.e. the parameter alias if it has one.
+func (e *Executor) arguments(c px.Context, ref *stepRef, s scope) scope {
+	args := newScope()
+	for _, p := range wf.StepParameters(ref.asStep(c)) {
+		v, ok := s[p.Name()]
+		if !ok {
+			if v = p.Value(); v == nil {
+				panic(px.Error(wf.UnresolvedParameter, issue.H{`step`: ref.definition, `name`: p.Name()}))
+			}
+			v = types.ResolveDeferred(c, v, s.hash())
+		}
+		args[inner(p)] = v
+	}
+	return args
+}
+
+// invokeForHash invokes the given method in the same way as invoke and raises an UnexpectedResult error unless the
+// result is a Hash. An undef result is returned as an empty Hash.
+func (e *Executor) invokeForHash(c px.Context, ref *stepRef, retry *wf.RetryPolicy, api, method string, arguments ...px.Value) px.OrderedMap {
+	switch result := e.invoke(c, ref, retry, api, method, arguments...).(type) {
+	case px.OrderedMap:
+		return result
+	case *types.UndefValue:
+		return px.EmptyMap
+	default:
+		panic(px.Error(UnexpectedResult, issue.H{`step`: ref.definition.Label(), `method`: method, `expected`: `Hash`, `actual`: result.PType()}))
+	}
+}
+
+// invoke invokes the given method on the API of the service of the given reference and raises an error if
+// the result is an ErrorObject. Failed invocations are retried according to the given retry policy.
+func (e *Executor) invoke(c px.Context, ref *stepRef, retry *wf.RetryPolicy, api, method string, arguments ...px.Value) px.Value {
+	result := retrying(c, retry, func() px.Value { return ref.service.Invoke(c, api, method, arguments...) })
+	e.check(ref, result)
+	return result
+}
+
+// failure is the issue that is raised when a step fails. It retains the ErrorObject that describes the
+// failure so that it can be passed to the handler of a guard.
+type failure struct {
+	issue.Reported
+	errorObject serviceapi.ErrorObject
+}
+
+func stepFailed(def serviceapi.Definition, eo serviceapi.ErrorObject) failure {
+	return failure{px.Error(StepFailed, issue.H{`step`: def.Label(), `message`: eo.Message()}), eo}
+}
+
+// guard executes the body of a guard. When the body fails, the handler is executed instead using a scope
+// where the ErrorObject that describes the failure is assigned to wf.GuardErrorParameter.
+func (e *Executor) guard(c px.Context, ref *stepRef, args scope) px.OrderedMap {
+	props := ref.definition.Properties()
+	body := &stepRef{service: ref.service, definition: props.Get5(`body`, px.Undef).(serviceapi.Definition)}
+	result, eo := e.try(c, body, args)
+	if eo == nil {
+		return result.hash()
+	}
+	handler := &stepRef{service: ref.service, definition: props.Get5(`handler`, px.Undef).(serviceapi.Definition)}
+	s := args.copy()
+	s[wf.GuardErrorParameter] = eo
+	result, _ = e.execute(c, handler, s)
+	return result.hash()
+}
+
+// selectCase executes the step of the first case of a switch with a condition that is true for the parameters
+// of the switch, or the default step of the switch when no such case exists
+func (e *Executor) selectCase(c px.Context, ref *stepRef, args scope) px.OrderedMap {
+	props := ref.definition.Properties()
+	cs := args.copy()
+	for _, p := range wf.StepParameters(ref.asStep(c)) {
+		if v, ok := args[inner(p)]; ok {
+			cs[p.Name()] = v
+		}
+	}
+	params := cs.hash()
+
+	var selected serviceapi.Definition
+	if cl, ok := props.Get5(`cases`, px.Undef).(px.List); ok {
+		for i := 0; i < cl.Len(); i++ {
+			cm := cl.At(i).(px.OrderedMap)
+			if wf.ToCondition(cm.Get5(`condition`, px.Undef)).IsTrue(params) {
+				selected = cm.Get5(`step`, px.Undef).(serviceapi.Definition)
+				break
+			}
+		}
+	}
+	if selected == nil {
+		if selected, _ = props.Get5(`default`, px.Undef).(serviceapi.Definition); selected == nil {
+			return px.EmptyMap
+		}
+	}
+	result, _ := e.execute(c, &stepRef{service: ref.service, definition: selected}, args)
+	return result.hash()
+}
+
+// wait reads the state of a resource, or invokes an API method, until the until condition of the wait holds
+// for the result. The interval between two attempts defaults to one second. An error is raised when the
+// timeout of the wait expires before the condition holds.
+func (e *Executor) wait(c px.Context, ref *stepRef, args scope) px.OrderedMap {
+	def := ref.definition
+	props := def.Properties()
+	until := wf.ToCondition(props.Get5(`until`, px.Undef))
+	interval := time.Second
+	if ts, ok := props.Get5(`interval`, px.Undef).(types.Timespan); ok {
+		interval = ts.Duration()
+	}
+	var deadline <-chan time.Time
+	if ts, ok := props.Get5(`timeout`, px.Undef).(types.Timespan); ok {
+		dt := time.NewTimer(ts.Duration())
+		defer dt.Stop()
+		deadline = dt.C
+	}
+
+	poll := e.poller(c, ref, args)
+	for {
+		result := poll()
+		if until.IsTrue(result) {
+			return result
+		}
+		t := time.NewTimer(interval)
+		select {
+		case <-t.C:
+		case <-deadline:
+			t.Stop()
+			panic(px.Error(WaitTimeout, issue.H{`step`: def.Label(), `condition`: until.String()}))
+		case <-c.Done():
+			t.Stop()
+			panic(px.Error(WaitCanceled, issue.H{`step`: def.Label()}))
+		}
+	}
+}
+
+// poller returns a function that reads the state of the resource of the given wait, or invokes its API method,
+// and returns the result as a hash. The state of a resource that doesn't exist yet is an empty hash.
+func (e *Executor) poller(c px.Context, ref *stepRef, args scope) func() px.OrderedMap {
+	props := ref.definition.Properties()
+	retry := retryPolicy(ref.definition)
+	if rn := service.StringProp(props, `resource`); rn != `` {
+		rd, ok := e.resources[rn]
+		if !ok {
+			panic(px.Error(NoSuchStep, issue.H{`name`: rn}))
+		}
+		tn := typeName(rd.definition.Properties().Get5(`resourceType`, px.Undef))
+		handler, ok := e.handlers[tn]
+		if !ok {
+			panic(px.Error(NoHandler, issue.H{`type`: tn}))
+		}
+		hn := handler.definition.Identifier().Name()
+		return func() px.OrderedMap {
+			extId, ok := e.identity.GetExternal(c, rn)
+			if !ok {
+				return px.EmptyMap
+			}
+			result := retrying(c, retry, func() px.Value { return handler.service.Invoke(c, hn, `read`, types.WrapString(extId)) })
+			if isNotFound(result) {
+				return px.EmptyMap
+			}
+			e.check(handler, result)
+			return asHash(result)
+		}
+	}
+	api := service.StringProp(props, `api`)
+	method := service.StringProp(props, `method`)
+	return func() px.OrderedMap { return asHash(e.invoke(c, ref, retry, api, method, args.hash())) }
+}
+
+// asHash returns the given value as a hash. An object is converted into a hash of its attributes. Any other
+// value that isn't a hash yields an empty hash.
+func asHash(v px.Value) px.OrderedMap {
+	switch v := v.(type) {
+	case px.OrderedMap:
+		return v
+	case px.PuppetObject:
+		return v.PType().(px.ObjectType).InstanceHash(v)
+	}
+	return px.EmptyMap
+}
+
+// try executes the given step and returns its result, or an ErrorObject that describes why it failed
+func (e *Executor) try(c px.Context, ref *stepRef, s scope) (result scope, eo serviceapi.ErrorObject) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case failure:
+				eo = r.errorObject
+			case issue.Reported:
+				eo = serviceapi.ErrorFromReported(c, r)
+			default:
+				panic(r)
+			}
+		}
+	}()
+	result, _ = e.execute(c, ref, s)
+	return
+}
+
+// runWorkflow executes the steps of a workflow in an order where each step is executed after the steps that
+// it depends on. A step that depends on a step that was skipped is also skipped. The returned map contains
+// the values returned by the executed steps.
+func (e *Executor) runWorkflow(c px.Context, ref *stepRef, args scope) px.OrderedMap {
+	var steps []*stepRef
+	if sl, ok := ref.definition.Properties().Get4(`steps`); ok {
+		sl.(px.List).Each(func(v px.Value) {
+			steps = append(steps, &stepRef{service: ref.service, definition: v.(serviceapi.Definition)})
+		})
+	}
+
+	returnedBy := make(map[string]int)
+	named := make(map[string]int)
+	for i, st := range steps {
+		for _, n := range wf.ReturnedNames(st.asStep(c)) {
+			returnedBy[n] = i
+		}
+		named[st.definition.Identifier().Name()] = i
+	}
+
+	pending := make([]map[int]bool, len(steps))
+	for i, st := range steps {
+		pending[i] = make(map[int]bool)
+		for _, p := range wf.StepParameters(st.asStep(c)) {
+			if pi, ok := returnedBy[p.Name()]; ok && pi != i {
+				pending[i][pi] = true
+			}
+		}
+		// A wait for a resource is executed after the resource
+		if ri, ok := named[service.StringProp(st.definition.Properties(), `resource`)]; ok && ri != i {
+			pending[i][ri] = true
+		}
+	}
+
+	s := args.copy()
+	produced := newScope()
+	done := make([]bool, len(steps))
+	skipped := make([]bool, len(steps))
+	for cnt := 0; cnt < len(steps); {
+		progress := false
+		for i, st := range steps {
+			if done[i] || len(pending[i]) > 0 {
+				continue
+			}
+			if !skipped[i] {
+				result, executed := e.execute(c, st, s)
+				if executed {
+					for k, v := range result {
+						s[k] = v
+						produced[k] = v
+					}
+				} else {
+					skipped[i] = true
+				}
+			}
+			done[i] = true
+			for j := range steps {
+				if pending[j][i] {
+					delete(pending[j], i)
+					if skipped[i] {
+						skipped[j] = true
+					}
+				}
+			}
+			progress = true
+			cnt++
+		}
+		if !progress {
+			names := make([]string, 0)
+			for i, st := range steps {
+				if !done[i] {
+					names = append(names, st.definition.Identifier().Name())
+				}
+			}
+			panic(px.Error(wf.DependencyCycle, issue.H{`steps`: strings.Join(names, `, `)}))
+		}
+	}
+	return produced.hash()
+}
+
+// applyResource resolves the desired state of a resource and applies it using the handler for the
+// state type. The returned map is the attributes of the resulting state.
+func (e *Executor) applyResource(c px.Context, ref *stepRef, args scope) px.OrderedMap {
+	def := ref.definition
+	name := def.Identifier().Name()
+	state := ref.service.State(c, name, args.hash())
+	e.check(ref, state)
+
+	tn := state.PType().Name()
+	handler, ok := e.handlers[tn]
+	if !ok {
+		panic(px.Error(NoHandler, issue.H{`type`: tn}))
+	}
+	hn := handler.definition.Identifier().Name()
+	hs := handler.service
+	retry := retryPolicy(def)
+
+	var result px.Value
+	if extId := service.StringProp(def.Properties(), `externalId`); extId != `` {
+		// Resource is not managed. Just read it.
+		result = e.invoke(c, handler, retry, hn, `read`, types.WrapString(extId))
+	} else if extId, ok := e.identity.GetExternal(c, name); ok {
+		if hasMethod(handler.definition, `update`) {
+			result = retrying(c, retry, func() px.Value { return hs.Invoke(c, hn, `update`, types.WrapString(extId), state) })
+		} else {
+			result = retrying(c, retry, func() px.Value { return hs.Invoke(c, hn, `delete`, types.WrapString(extId)) })
+			if !isNotFound(result) {
+				e.check(handler, result)
+			}
+			result = nil
+		}
+		if result == nil || isNotFound(result) {
+			result = e.create(c, handler, retry, name, state)
+		} else {
+			e.check(handler, result)
+		}
+	} else {
+		result = e.create(c, handler, retry, name, state)
+	}
+
+	return asHash(result)
+}
+
+// create creates the given state using the given handler and associates the resulting external id
+// with the resource name
+func (e *Executor) create(c px.Context, handler *stepRef, retry *wf.RetryPolicy, name string, state px.Value) px.Value {
+	result := e.invoke(c, handler, retry, handler.definition.Identifier().Name(), `create`, state)
+	tuple, ok := result.(px.List)
+	if !ok || tuple.Len() != 2 {
+		panic(px.Error(UnexpectedResult, issue.H{`step`: handler.definition.Label(), `method`: `create`, `expected`: `Tuple[Object, String]`, `actual`: result.PType()}))
+	}
+	e.identity.Associate(c, name, tuple.At(1).String())
+	return tuple.At(0)
+}
+
+// retrying calls the given function until it returns something other than an ErrorObject that is retryable
+// according to the given policy, or until the maximum number of attempts has been made. The function is
+// called once when the policy is nil. An invocation that was canceled or that reports that something was not
+// found is never retried.
+func retrying(c px.Context, policy *wf.RetryPolicy, f func() px.Value) px.Value {
+	result := attempt(c, f)
+	if policy == nil {
+		return result
+	}
+	for retry := 1; retry < policy.MaxAttempts; retry++ {
+		eo, ok := result.(serviceapi.ErrorObject)
+		if !ok || eo.Kind() == serviceapi.CanceledKind || isNotFound(eo) || !policy.Retryable(eo.Kind(), eo.IssueCode()) {
+			break
+		}
+		t := time.NewTimer(policy.Delay(retry))
+		select {
+		case <-t.C:
+		case <-c.Done():
+			t.Stop()
+			return result
+		}
+		result = attempt(c, f)
+	}
+	return result
+}
+
+// attempt calls the given function and converts a reported issue that it raises into an ErrorObject
+func attempt(c px.Context, f func() px.Value) (result px.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			if ri, ok := r.(issue.Reported); ok {
+				result = serviceapi.ErrorFromReported(c, ri)
+				return
+			}
+			panic(r)
+		}
+	}()
+	return f()
+}
+
+func retryPolicy(def serviceapi.Definition) *wf.RetryPolicy {
+	if rp, ok := def.Properties().Get5(`retry`, px.Undef).(*wf.RetryPolicy); ok {
+		return rp
+	}
+	return nil
+}
+
+func (e *Executor) check(ref *stepRef, result px.Value) {
+	if eo, ok := result.(serviceapi.ErrorObject); ok {
+		panic(stepFailed(ref.definition, eo))
+	}
+}
+
+// iterate executes the producer of an iterator once for each iteration and collects the results. The iterations
+// are executed sequentially, which satisfies any parallelism limit of the iterator. When the iterator collects
+// errors, all iterations are executed and the failures are reported together. Otherwise, the first failure is
+// reported immediately.
+func (e *Executor) iterate(c px.Context, ref *stepRef, args scope) px.OrderedMap {
+	def := ref.definition
+	props := def.Properties()
+	pv, ok := props.Get4(`producer`)
+	if !ok {
+		return px.EmptyMap
+	}
+	producer := &stepRef{service: ref.service, definition: pv.(serviceapi.Definition)}
+
+	over := props.Get5(`over`, px.Undef)
+	if p, ok := over.(serviceapi.Parameter); ok {
+		over = get(args.hash(), inner(p))
+	}
+	vars := service.ParamsProp(props, `variables`)
+	style := service.StringProp(props, `iterationStyle`)
+	if style == `while` || style == `until` {
+		return e.loop(c, ref, producer, args, vars, style == `while`)
+	}
+
+	iterations := make([][]px.Value, 0)
+	bad := func() {
+		panic(px.Error(IllegalIterationOver, issue.H{`step`: def.Label(), `style`: style, `value`: over}))
+	}
+	switch style {
+	case `times`:
+		n, ok := over.(px.Integer)
+		if !ok {
+			bad()
+		}
+		for i := int64(0); i < n.Int(); i++ {
+			iterations = append(iterations, []px.Value{types.WrapInteger(i)})
+		}
+	case `range`:
+		r, ok := over.(px.List)
+		if !ok || r.Len() != 2 {
+			bad()
+		}
+		from, ok1 := r.At(0).(px.Integer)
+		to, ok2 := r.At(1).(px.Integer)
+		if !(ok1 && ok2) {
+			bad()
+		}
+		for i := from.Int(); i <= to.Int(); i++ {
+			iterations = append(iterations, []px.Value{types.WrapInteger(i)})
+		}
+	case `each`:
+		switch over := over.(type) {
+		case px.OrderedMap:
+			over.EachPair(func(k, v px.Value) { iterations = append(iterations, []px.Value{types.WrapValues([]px.Value{k, v})}) })
+		case px.List:
+			over.Each(func(v px.Value) { iterations = append(iterations, []px.Value{v}) })
+		default:
+			bad()
+		}
+		if len(vars) > 1 {
+			// Spread each element over the variables
+			for i, it := range iterations {
+				if l, ok := it[0].(px.List); ok {
+					iterations[i] = l.AppendTo(make([]px.Value, 0, l.Len()))
+				}
+			}
+		}
+	case `eachPair`:
+		h, ok := over.(px.OrderedMap)
+		if !ok {
+			bad()
+		}
+		h.EachPair(func(k, v px.Value) { iterations = append(iterations, []px.Value{k, v}) })
+	default:
+		bad()
+	}
+
+	producerReturns := wf.ReturnedNames(producer.asStep(c))
+	collectErrors := false
+	if ce, ok := props.Get5(`collectErrors`, px.Undef).(px.Boolean); ok {
+		collectErrors = ce.Bool()
+	}
+	collected := make([]px.Value, len(iterations))
+	var failures []string
+	for i, it := range iterations {
+		s := args.copy()
+		for vi, v := range vars {
+			if vi < len(it) {
+				s[v.Name()] = it[vi]
+			} else {
+				s[v.Name()] = px.Undef
+			}
+		}
+		if !collectErrors {
+			result, _ := e.execute(c, producer, s)
+			collected[i] = collect(result, producerReturns)
+			continue
+		}
+		result, eo := e.try(c, producer, s)
+		if eo != nil {
+			failures = append(failures, eo.Message())
+			collected[i] = px.Undef
+		} else {
+			collected[i] = collect(result, producerReturns)
+		}
+	}
+	if len(failures) > 0 {
+		panic(px.Error(IterationsFailed, issue.H{`step`: def.Label(), `count`: len(failures), `messages`: strings.Join(failures, `; `)}))
+	}
+	return px.SingletonMap(intoName(def), types.WrapValues(collected))
+}
+
+// loop executes the producer of a while or until iterator repeatedly. The condition of the iterator is evaluated
+// using the returns of the producer after each iteration. Another iteration runs while the condition is true, or
+// until it is true, as determined by the given while flag. The returns of each iteration are passed as parameters
+// to the next iteration. The first variable, if any, is assigned the number of the iteration.
+func (e *Executor) loop(c px.Context, ref, producer *stepRef, args scope, vars []serviceapi.Parameter, while bool) px.OrderedMap {
+	def := ref.definition
+	props := def.Properties()
+	cond := wf.ToCondition(props.Get5(`over`, px.Undef))
+	max := wf.DefaultMaxIterations
+	if mi, ok := props.Get5(`maxIterations`, px.Undef).(px.Integer); ok {
+		max = int(mi.Int())
+	}
+
+	producerReturns := wf.ReturnedNames(producer.asStep(c))
+	collected := make([]px.Value, 0)
+	s := args.copy()
+	for _, n := range wf.LoopParameters(producer.asStep(c)) {
+		if _, ok := s[n]; !ok {
+			s[n] = px.Undef
+		}
+	}
+	for i := 0; ; i++ {
+		if i == max {
+			panic(px.Error(MaxIterationsExceeded, issue.H{`step`: def.Label(), `max`: max}))
+		}
+		if len(vars) > 0 {
+			s[vars[0].Name()] = types.WrapInteger(int64(i))
+		}
+		result, _ := e.execute(c, producer, s)
+		collected = append(collected, collect(result, producerReturns))
+		if cond.IsTrue(result.hash()) != while {
+			break
+		}
+		for k, v := range result {
+			s[k] = v
+		}
+	}
+	return px.SingletonMap(intoName(def), types.WrapValues(collected))
+}
+
+// collect returns the value that is collected from the given result of a producer. This is the single
+// returned value when the producer has one return and a hash of all returned values otherwise.
+func collect(result scope, producerReturns []string) px.Value {
+	if len(producerReturns) == 1 {
+		return result[producerReturns[0]]
+	}
+	return result.hash()
+}
+
+func intoName(def serviceapi.Definition) string {
+	if into := service.StringProp(def.Properties(), `into`); into != `` {
+		return into
+	}
+	return wf.LeafName(def.Identifier().Name())
+}
+
+// inner returns the name of the given parameter as seen inside of the step that declares it
+func inner(p serviceapi.Parameter) string {
+	if a := p.Alias(); a != `` {
+		return a
+	}
+	return p.Name()
+}
+
+func get(m px.OrderedMap, key string) px.Value {
+	if v, ok := m.Get4(key); ok {
+		return v
+	}
+	return px.Undef
+}
+
+func hasMethod(def serviceapi.Definition, name string) bool {
+	if it, ok := def.Properties().Get4(`interface`); ok {
+		if ct, ok := it.(px.TypeWithCallableMembers); ok {
+			_, ok = ct.Member(name)
+			return ok
+		}
+	}
+	return false
+}
+
+// isNotFound returns true if the given value is an ErrorObject that represents the NotFound error, or that is
+// caused by it.
+func isNotFound(v px.Value) bool {
+	eo, ok := v.(serviceapi.ErrorObject)
+	if !ok {
+		return false
+	}
+	if eo.IssueCode() == service.NotFound {
+		return true
+	}
+	if cause, ok := eo.Details().Get5(`cause`, px.Undef).(serviceapi.ErrorObject); ok {
+		return isNotFound(cause)
+	}
+	return false
+}
+
+func typeName(v px.Value) string {
+	if t, ok := v.(px.Type); ok {
+		return t.Name()
+	}
+	return v.String()
+}
+
+// scope is a set of named values
+type scope map[string]px.Value
+
+func newScope() scope {
+	return make(scope)
+}
+
+func (s scope) copy() scope {
+	c := make(scope, len(s))
+	for k, v := range s {
+		c[k] = v
+	}
+	return c
+}
+
+// hash returns the scope as a px.OrderedMap with the keys sorted alphabetically
+func (s scope) hash() px.OrderedMap {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	es := make([]*types.HashEntry, len(keys))
+	for i, k := range keys {
+		es[i] = types.WrapHashEntry2(k, s[k])
+	}
+	return types.WrapHash(es)
+}
@@ -0,0 +1,557 @@
+package executor_test
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/executor"
+	"github.com/lyraproj/servicesdk/lang/go/lyra"
+	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+type Owner struct {
+	Id    *string
+	Name  string
+	Phone string
+}
+
+type ownerHandler struct {
+	owners map[string]*Owner
+}
+
+func (h *ownerHandler) Create(o *Owner) (*Owner, string) {
+	id := strconv.Itoa(len(h.owners) + 1)
+	o.Id = &id
+	h.owners[id] = o
+	fmt.Println(`create`, id, o.Name, o.Phone)
+	return o, id
+}
+
+func (h *ownerHandler) Read(id string) (*Owner, error) {
+	if o, ok := h.owners[id]; ok {
+		return o, nil
+	}
+	return nil, wf.NotFound
+}
+
+func (h *ownerHandler) Update(id string, o *Owner) (*Owner, error) {
+	if _, ok := h.owners[id]; !ok {
+		return nil, wf.NotFound
+	}
+	o.Id = &id
+	h.owners[id] = o
+	fmt.Println(`update`, id, o.Name, o.Phone)
+	return o, nil
+}
+
+func (h *ownerHandler) Delete(id string) error {
+	if _, ok := h.owners[id]; !ok {
+		return wf.NotFound
+	}
+	delete(h.owners, id)
+	return nil
+}
+
+func ExampleExecutor_Run() {
+	type phoneOut struct {
+		Phone string
+	}
+
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		ts := sb.RegisterTypes(`My`, &Owner{})
+		px.AddTypes(c, ts...)
+		handler := &ownerHandler{map[string]*Owner{}}
+		sb.RegisterHandler(`My::OwnerHandler`, handler, ts[0])
+		sb.RegisterStateConverter(lyra.StateConverter)
+		sb.RegisterStep((&lyra.Workflow{
+			Parameters: struct{ Name string }{},
+			Return:     struct{ OwnerId string }{},
+			Steps: map[string]lyra.Step{
+				`owner`: &lyra.Resource{
+					Return: struct {
+						OwnerId string `alias:"id"`
+					}{},
+					State: func(in struct {
+						Name  string
+						Phone string
+					}) *Owner {
+						return &Owner{Name: in.Name, Phone: in.Phone}
+					}},
+				`phone`: &lyra.Action{
+					Do: func(in struct{ Name string }) phoneOut {
+						return phoneOut{`555-` + in.Name}
+					}},
+				`never`: &lyra.Action{
+					When: `!name`,
+					Do: func() {
+						fmt.Println(`never called`)
+					}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		params := px.SingletonMap(`name`, types.WrapString(`bob`))
+		fmt.Println(e.Run(c, `My::Test`, params))
+		fmt.Println(e.Run(c, `My::Test`, params))
+
+		// Resource deleted outside of the workflow
+		delete(handler.owners, `1`)
+		fmt.Println(e.Run(c, `My::Test`, params))
+	})
+
+	// Output:
+	// create 1 bob 555-bob
+	// {'ownerId' => '1'}
+	// update 1 bob 555-bob
+	// {'ownerId' => '1'}
+	// create 1 bob 555-bob
+	// {'ownerId' => '1'}
+}
+
+func ExampleExecutor_Run_iterator() {
+	type out struct {
+		Square int
+	}
+
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Collect{
+			Times:  4,
+			As:     `n`,
+			Return: `squares`,
+			Step: &lyra.Action{
+				Do: func(in struct{ N int }) out {
+					return out{in.N * in.N}
+				}}}).Resolve(c, `My::Squares`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		fmt.Println(e.Run(c, `My::Squares`, px.EmptyMap))
+	})
+
+	// Output:
+	// {'squares' => [0, 1, 4, 9]}
+}
+
+func ExampleExecutor_Run_skipped() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Action{
+			When: `enabled`,
+			Do: func(in struct {
+				Enabled bool
+				Token   string
+			}) {
+				fmt.Println(`notify`, in.Token)
+			}}).Resolve(c, `My::Notify`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		// No token is needed when the step is skipped
+		e := executor.New(c, nil, sb.Server())
+		fmt.Println(e.Run(c, `My::Notify`, px.SingletonMap(`enabled`, types.BooleanFalse)))
+		e.Run(c, `My::Notify`, px.Wrap(c, map[string]interface{}{`enabled`: true, `token`: `secret`}).(px.OrderedMap))
+	})
+
+	// Output:
+	// {}
+	// notify secret
+}
+
+func ExampleExecutor_Run_unexpectedResult() {
+	err := pcore.Try(func(c px.Context) error {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.UseInterceptor(func(c px.Context, api, method string, arguments []px.Value, next service.Invoker) px.Value {
+			next(c, api, method, arguments)
+			return types.WrapString(`done`)
+		})
+		sb.RegisterStep((&lyra.Action{Do: func() {}}).Resolve(c, `My::Act`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		e.Run(c, `My::Act`, px.EmptyMap)
+		return nil
+	})
+	r := err.(issue.Reported)
+	fmt.Println(r.Code(), r.Argument(`actual`))
+
+	// Output:
+	// WF_UNEXPECTED_RESULT String
+}
+
+func ExampleExecutor_Run_retry() {
+	type out struct {
+		Zone string
+	}
+
+	pcore.Do(func(c px.Context) {
+		attempts := 0
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Action{
+			Retry: &wf.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			Do: func() (*out, error) {
+				attempts++
+				if attempts < 3 {
+					return nil, fmt.Errorf(`zone not yet available`)
+				}
+				return &out{`eu-1a`}, nil
+			}}).Resolve(c, `My::Zone`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		fmt.Println(e.Run(c, `My::Zone`, px.EmptyMap), attempts)
+	})
+
+	// Output:
+	// {'zone' => 'eu-1a'} 3
+}
+
+func ExampleExecutor_Run_guard() {
+	type out struct {
+		Address string
+	}
+
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Guard{
+			Body: &lyra.Action{
+				Do: func(in struct{ Host string }) (*out, error) {
+					return nil, fmt.Errorf(`no such host %s`, in.Host)
+				}},
+			Handler: &lyra.Action{
+				Do: func(in struct {
+					Host  string
+					Error serviceapi.ErrorObject
+				}) *out {
+					fmt.Println(in.Error.IssueCode())
+					return &out{`127.0.0.1`}
+				}}}).Resolve(c, `My::Lookup`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		fmt.Println(e.Run(c, `My::Lookup`, px.SingletonMap(`host`, types.WrapString(`example.com`))))
+	})
+
+	// Output:
+	// WF_ACTION_EXECUTION_ERROR
+	// {'address' => '127.0.0.1'}
+}
+
+func ExampleExecutor_Run_switch() {
+	type out struct {
+		Size string
+	}
+
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Switch{
+			Parameters: struct{ Count int }{},
+			Cases: []lyra.Case{
+				{When: `count > 100`, Step: &lyra.Action{Do: func() out { return out{`large`} }}},
+				{When: `count > 10`, Step: &lyra.Action{Do: func() out { return out{`medium`} }}}},
+			Default: &lyra.Action{Do: func() out { return out{`small`} }},
+		}).Resolve(c, `My::Size`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		for _, n := range []int64{500, 50, 5} {
+			fmt.Println(e.Run(c, `My::Size`, px.SingletonMap(`count`, types.WrapInteger(n))))
+		}
+	})
+
+	// Output:
+	// {'size' => 'large'}
+	// {'size' => 'medium'}
+	// {'size' => 'small'}
+}
+
+func ExampleExecutor_Run_switchReturnsMismatch() {
+	type out struct {
+		Size string
+	}
+
+	err := pcore.Try(func(c px.Context) error {
+		(&lyra.Switch{
+			Cases: []lyra.Case{
+				{When: `large`, Step: &lyra.Action{Do: func() out { return out{`large`} }}}},
+			Default: &lyra.Action{Do: func() {}},
+		}).Resolve(c, `My::Size`, issue.ParseLocation(`(file: /test/x.go)`))
+		return nil
+	})
+	fmt.Println(err)
+
+	// Output:
+	// action My::Size::default returns [] but switch My::Size returns [size] (file: /test/x.go)
+}
+
+type Database struct {
+	Id     *string
+	Name   string
+	Status string
+}
+
+type databaseHandler struct {
+	databases map[string]*Database
+	reads     int
+}
+
+func (h *databaseHandler) Create(d *Database) (*Database, string) {
+	id := strconv.Itoa(len(h.databases) + 1)
+	d.Id = &id
+	d.Status = `creating`
+	h.databases[id] = d
+	return d, id
+}
+
+func (h *databaseHandler) Read(id string) (*Database, error) {
+	if d, ok := h.databases[id]; ok {
+		h.reads++
+		if h.reads == 3 {
+			d.Status = `available`
+		}
+		return d, nil
+	}
+	return nil, wf.NotFound
+}
+
+func (h *databaseHandler) Delete(id string) error {
+	delete(h.databases, id)
+	return nil
+}
+
+func ExampleExecutor_Run_wait() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		ts := sb.RegisterTypes(`My`, &Database{})
+		px.AddTypes(c, ts...)
+		handler := &databaseHandler{databases: map[string]*Database{}}
+		sb.RegisterHandler(`My::DatabaseHandler`, handler, ts[0])
+		sb.RegisterStateConverter(lyra.StateConverter)
+		sb.RegisterStep((&lyra.Workflow{
+			Return: struct{ Status string }{},
+			Steps: map[string]lyra.Step{
+				`db`: &lyra.Resource{
+					State: func() *Database {
+						return &Database{Name: `orders`}
+					}},
+				`ready`: &lyra.Wait{
+					Resource: `db`,
+					Until:    `status == 'available'`,
+					Interval: time.Millisecond,
+					Timeout:  time.Second,
+					Return:   struct{ Status string }{}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		fmt.Println(e.Run(c, `My::Test`, px.EmptyMap), handler.reads)
+	})
+
+	// Output:
+	// {'status' => 'available'} 3
+}
+
+func ExampleExecutor_Run_until() {
+	type page struct {
+		Items     []string
+		NextToken string
+	}
+	pages := map[string]page{
+		``:   {[]string{`a`, `b`}, `p2`},
+		`p2`: {[]string{`c`, `d`}, `p3`},
+		`p3`: {[]string{`e`}, ``}}
+
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Collect{
+			Until:  `nextToken == ''`,
+			Return: `pages`,
+			Step: &lyra.Action{
+				Do: func(in struct{ NextToken string }) page {
+					return pages[in.NextToken]
+				}}}).Resolve(c, `My::List`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		fmt.Println(e.Run(c, `My::List`, px.EmptyMap))
+	})
+
+	// Output:
+	// {'pages' => [{'items' => ['a', 'b'], 'nextToken' => 'p2'}, {'items' => ['c', 'd'], 'nextToken' => 'p3'}, {'items' => ['e'], 'nextToken' => ''}]}
+}
+
+func ExampleExecutor_Run_maxIterations() {
+	type out struct {
+		More bool
+	}
+
+	err := pcore.Try(func(c px.Context) error {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Collect{
+			While:         `more`,
+			MaxIterations: 3,
+			Step: &lyra.Action{
+				Do: func() out { return out{true} }}}).Resolve(c, `My::Forever`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		e.Run(c, `My::Forever`, px.EmptyMap)
+		return nil
+	})
+	fmt.Println(err.(issue.Reported).Code())
+
+	// Output:
+	// WF_MAX_ITERATIONS_EXCEEDED
+}
+
+func ExampleExecutor_Run_rangeAndEachPair() {
+	type out struct {
+		Line string
+	}
+
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Collect{
+			Range:       []int{3, 5},
+			Parallelism: 2,
+			As:          `n`,
+			Return:      `lines`,
+			Step: &lyra.Action{
+				Do: func(in struct{ N int }) out {
+					return out{fmt.Sprintf(`line %d`, in.N)}
+				}}}).Resolve(c, `My::Lines`, issue.ParseLocation(`(file: /test/x.go)`)))
+		sb.RegisterStep((&lyra.Collect{
+			EachPair: map[string]int{`b`: 2, `a`: 1},
+			As:       []string{`key`, `value`},
+			Return:   `lines`,
+			Step: &lyra.Action{
+				Do: func(in struct {
+					Key   string
+					Value int
+				}) out {
+					return out{fmt.Sprintf(`%s=%d`, in.Key, in.Value)}
+				}}}).Resolve(c, `My::Pairs`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		s := sb.Server()
+		_, defs := s.Metadata(c)
+		fmt.Println(defs[0].Properties().Get5(`parallelism`, px.Undef))
+
+		e := executor.New(c, nil, s)
+		fmt.Println(e.Run(c, `My::Lines`, px.EmptyMap))
+		fmt.Println(e.Run(c, `My::Pairs`, px.EmptyMap))
+	})
+
+	// Output:
+	// 2
+	// {'lines' => ['line 3', 'line 4', 'line 5']}
+	// {'lines' => ['a=1', 'b=2']}
+}
+
+func ExampleExecutor_Run_collectErrors() {
+	type out struct {
+		Host string
+	}
+
+	err := pcore.Try(func(c px.Context) error {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Collect{
+			Each:          []string{`a`, `b`, `c`},
+			CollectErrors: true,
+			As:            `name`,
+			Step: &lyra.Action{
+				Do: func(in struct{ Name string }) (*out, error) {
+					fmt.Println(`pinging`, in.Name)
+					if in.Name == `c` {
+						return &out{in.Name}, nil
+					}
+					return nil, fmt.Errorf(`%s is down`, in.Name)
+				}}}).Resolve(c, `My::Ping`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		e.Run(c, `My::Ping`, px.EmptyMap)
+		return nil
+	})
+	fmt.Println(err.(issue.Reported).Code())
+
+	// Output:
+	// pinging a
+	// pinging b
+	// pinging c
+	// WF_ITERATIONS_FAILED
+}
+
+func ExampleExecutor_Run_stateHandler() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		ts := sb.RegisterTypes(`My`, &Owner{})
+		px.AddTypes(c, ts...)
+		sb.RegisterStateConverter(lyra.StateConverter)
+		sb.RegisterStep((&lyra.Workflow{
+			Steps: map[string]lyra.Step{
+				`handler`: &lyra.StateHandler{
+					State:   &Owner{},
+					Handler: &ownerHandler{map[string]*Owner{}}},
+				`owner`: &lyra.Resource{
+					State: func() *Owner {
+						return &Owner{Name: `bob`, Phone: `555-1234`}
+					}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		e.Run(c, `My::Test`, px.EmptyMap)
+		e.Run(c, `My::Test`, px.EmptyMap)
+	})
+
+	// Output:
+	// create 1 bob 555-1234
+	// update 1 bob 555-1234
+}
+
+func ExampleExecutor_Run_crudHandler() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		ts := sb.RegisterTypes(`My`, &Owner{})
+		px.AddTypes(c, ts...)
+		owners := map[string]*Owner{}
+		handler := lyra.NewCrudHandler(c, &ownerHandler{owners}, &Owner{})
+		sb.RegisterHandler(`My::OwnerHandler`, handler, handler.StateType())
+		sb.RegisterStateConverter(lyra.StateConverter)
+		sb.RegisterStep((&lyra.Workflow{
+			Steps: map[string]lyra.Step{
+				`owner`: &lyra.Resource{
+					State: func() *Owner {
+						return &Owner{Name: `bob`, Phone: `555-1234`}
+					}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		e := executor.New(c, nil, sb.Server())
+		e.Run(c, `My::Test`, px.EmptyMap)
+
+		// The handler reports that the owner is not found so it is created again
+		delete(owners, `1`)
+		e.Run(c, `My::Test`, px.EmptyMap)
+	})
+
+	// Output:
+	// create 1 bob 555-1234
+	// create 1 bob 555-1234
+}
+
+func ExampleExecutor_Run_stateStruct() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		ts := sb.RegisterTypes(`My`, &Owner{})
+		px.AddTypes(c, ts...)
+		sb.RegisterHandler(`My::OwnerHandler`, &ownerHandler{map[string]*Owner{}}, ts[0])
+		sb.RegisterStep(wf.NewWorkflow(c, func(w wf.WorkflowBuilder) {
+			w.Name(`My::Test`)
+			w.Parameters(w.Parameter(`name`, `String`), w.Parameter(`area`, `String`))
+			w.Resource(func(r wf.ResourceBuilder) {
+				r.Name(`owner`)
+				r.Parameters(r.Parameter(`name`, `String`), r.Parameter(`area`, `String`))
+				r.StateStruct(&Owner{Name: `$name`, Phone: `${area}-1234`})
+			})
+		}))
+
+		e := executor.New(c, nil, sb.Server())
+		e.Run(c, `My::Test`, types.WrapHash([]*types.HashEntry{
+			types.WrapHashEntry2(`name`, types.WrapString(`bob`)),
+			types.WrapHashEntry2(`area`, types.WrapString(`555`))}))
+	})
+
+	// Output:
+	// create 1 bob 555-1234
+}
@@ -0,0 +1,29 @@
+package executor
+
+import "github.com/lyraproj/issue/issue"
+
+const (
+	IllegalIterationOver  = `WF_ILLEGAL_ITERATION_OVER`
+	IterationsFailed      = `WF_ITERATIONS_FAILED`
+	MaxIterationsExceeded = `WF_MAX_ITERATIONS_EXCEEDED`
+	NoHandler             = `WF_NO_HANDLER`
+	NoSuchStep            = `WF_NO_SUCH_STEP`
+	StepFailed            = `WF_STEP_FAILED`
+	UnexpectedResult      = `WF_UNEXPECTED_RESULT`
+	UnknownStepStyle      = `WF_UNKNOWN_STEP_STYLE`
+	WaitCanceled          = `WF_WAIT_CANCELED`
+	WaitTimeout           = `WF_WAIT_TIMEOUT`
+)
+
+func init() {
+	issue.Hard(IllegalIterationOver, `%{step}: cannot iterate using style %{style} over %{value}`)
+	issue.Hard(IterationsFailed, `%{step}: %{count} iterations failed: %{messages}`)
+	issue.Hard(MaxIterationsExceeded, `%{step} exceeded the maximum of %{max} iterations`)
+	issue.Hard(NoHandler, `no handler has been registered for resource type %{type}`)
+	issue.Hard(NoSuchStep, `no service defines a step named '%{name}'`)
+	issue.Hard(StepFailed, `%{step} failed: %{message}`)
+	issue.Hard(UnexpectedResult, `%{step}: %{method} returned a %{actual} where a %{expected} was expected`)
+	issue.Hard(UnknownStepStyle, `%{step} has an unknown style '%{style}'`)
+	issue.Hard(WaitCanceled, `%{step} was canceled`)
+	issue.Hard(WaitTimeout, `%{step} timed out waiting for '%{condition}'`)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,15 +12,16 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
+	"github.com/lyraproj/data-protobuf/datapb"
 	"github.com/lyraproj/issue/issue"
 	"github.com/lyraproj/pcore/px"
 	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/service"
 	"github.com/lyraproj/servicesdk/serviceapi"
 	"github.com/lyraproj/servicesdk/servicepb"
 	"google.golang.org/grpc"
-
-	// Ensure that service is initialized
-	_ "github.com/lyraproj/servicesdk/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var handshake = plugin.HandshakeConfig{
@@ -44,6 +46,15 @@ type Client struct {
 	client servicepb.DefinitionServiceClient
 }
 
+// Cancel aborts the remote Invoke or State call that was started with a context carrying the given invocation id
+func (c *Client) Cancel(ctx px.Context, invocationId string) bool {
+	rr, err := c.client.Cancel(ctx, &servicepb.CancelRequest{InvocationId: invocationId})
+	if err != nil {
+		panic(err)
+	}
+	return rr.Canceled
+}
+
 func (c *Client) Identifier(ctx px.Context) px.TypedName {
 	rr, err := c.client.Identity(ctx, &servicepb.EmptyRequest{})
 	if err != nil {
@@ -52,67 +63,131 @@ func (c *Client) Identifier(ctx px.Context) px.TypedName {
 	return FromDataPB(ctx, rr).(px.TypedName)
 }
 
+// Invoke invokes the given method on the remote service. If the given context carries a progress emitter (see
+// service.WithProgressEmitter), then that emitter is called with each progress event reported by the remote handler.
 func (c *Client) Invoke(ctx px.Context, identifier, name string, arguments ...px.Value) px.Value {
 	rq := servicepb.InvokeRequest{
-		Identifier: identifier,
-		Method:     name,
-		Arguments:  ToDataPB(ctx, types.WrapValues(arguments)),
+		Identifier:   identifier,
+		Method:       name,
+		Arguments:    ToDataPB(ctx, types.WrapValues(arguments)),
+		InvocationId: service.InvocationId(ctx),
+	}
+	var rr *datapb.Data
+	var err error
+	if emitter := service.ProgressEmitter(ctx); emitter != nil {
+		rr, err = c.invokeStream(ctx, &rq, emitter)
+	} else {
+		rr, err = c.client.Invoke(ctx, &rq)
 	}
-	rr, err := c.client.Invoke(ctx, &rq)
 	if err != nil {
-		panic(err)
+		panic(rpcError(err, identifier, name))
 	}
 	result := FromDataPB(ctx, rr)
 	if eo, ok := result.(serviceapi.ErrorObject); ok {
-		var cause error
-		if re, ok := eo.ToReported(); ok {
-			cause = re
-		} else {
-			cause = errors.New(eo.Message())
+		panic(invocationError(eo, identifier, name))
+	}
+	return result
+}
+
+// invokeStream performs a streaming invocation and passes all progress events to the given emitter. A
+// unary invocation is performed instead when the server doesn't implement streaming invocations. It is an
+// error if the stream ends without a result.
+func (c *Client) invokeStream(ctx px.Context, rq *servicepb.InvokeRequest, emitter serviceapi.ProgressEmitter) (*datapb.Data, error) {
+	stream, err := c.client.InvokeStream(ctx, rq)
+	if err != nil {
+		return nil, err
+	}
+	var result *datapb.Data
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			if result == nil {
+				return nil, px.Error(StreamWithoutResult, issue.H{`identifier`: rq.Identifier, `name`: rq.Method})
+			}
+			return result, nil
 		}
-		var errHost, errExe string
-		dm := eo.Details()
-		if dm != nil {
-			var v px.Value
-			if v, ok = dm.Get4(`host`); ok {
-				errHost = v.String()
-				host, _ := os.Hostname()
-				if host == errHost {
-					errHost = ``
-				}
+		if err != nil {
+			if status.Code(err) == codes.Unimplemented {
+				return c.client.Invoke(ctx, rq)
+			}
+			return nil, err
+		}
+		if p := ev.GetProgress(); p != nil {
+			pe := &serviceapi.ProgressEvent{Percent: p.Percent, Message: p.Message}
+			if fs, ok := FromDataPB(ctx, p.Fields).(px.OrderedMap); ok {
+				pe.Fields = fs
 			}
-			if v, ok = dm.Get4(`executable`); ok {
-				errExe = v.String()
-				if errHost == `` {
-					exe, _ := os.Executable()
-					if exe == errExe {
-						errExe = ``
-					} else {
-						// Strip working dir from the executable if it is relative to it
-						if wd, err := os.Getwd(); err == nil {
-							if strings.HasPrefix(errExe, wd) {
-								errExe = errExe[len(wd)+1:]
-							}
+			emitter(pe)
+		}
+		if r := ev.GetResult(); r != nil {
+			result = r
+		}
+	}
+}
+
+// rpcError converts errors caused by an elapsed deadline or a canceled context into the
+// corresponding invocation issue. Other errors are returned unchanged.
+func rpcError(err error, identifier, name string) error {
+	var code issue.Code
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		code = service.InvocationTimeout
+	case codes.Canceled:
+		code = service.InvocationCanceled
+	default:
+		return err
+	}
+	return issue.NewNested(InvocationError, issue.H{`identifier`: identifier, `name`: name}, 0,
+		px.Error(code, issue.H{`identifier`: identifier, `name`: name}))
+}
+
+// invocationError creates the error that describes the given ErrorObject returned from a remote invocation
+func invocationError(eo serviceapi.ErrorObject, identifier, name string) error {
+	var cause error
+	if re, ok := eo.ToReported(); ok {
+		cause = re
+	} else {
+		cause = errors.New(eo.Message())
+	}
+	var errHost, errExe string
+	dm := eo.Details()
+	if dm != nil {
+		var v px.Value
+		var ok bool
+		if v, ok = dm.Get4(`host`); ok {
+			errHost = v.String()
+			host, _ := os.Hostname()
+			if host == errHost {
+				errHost = ``
+			}
+		}
+		if v, ok = dm.Get4(`executable`); ok {
+			errExe = v.String()
+			if errHost == `` {
+				exe, _ := os.Executable()
+				if exe == errExe {
+					errExe = ``
+				} else {
+					// Strip working dir from the executable if it is relative to it
+					if wd, err := os.Getwd(); err == nil {
+						if strings.HasPrefix(errExe, wd) {
+							errExe = errExe[len(wd)+1:]
 						}
 					}
 				}
 			}
 		}
+	}
 
-		if errExe != `` {
-			if errHost != `` {
-				cause = issue.NewNested(RemoteInvocationError, issue.H{
-					`host`: errHost, `executable`: errExe, `identifier`: identifier, `name`: name}, 0, cause)
-			} else {
-				cause = issue.NewNested(ProcInvocationError, issue.H{
-					`executable`: errExe, `identifier`: identifier, `name`: name}, 0, cause)
-			}
-		} else {
-			cause = issue.NewNested(InvocationError, issue.H{`identifier`: identifier, `name`: name}, 0, cause)
+	if errExe != `` {
+		if errHost != `` {
+			return issue.NewNested(RemoteInvocationError, issue.H{
+				`host`: errHost, `executable`: errExe, `identifier`: identifier, `name`: name}, 0, cause)
 		}
-		panic(cause)
+		return issue.NewNested(ProcInvocationError, issue.H{
+			`executable`: errExe, `identifier`: identifier, `name`: name}, 0, cause)
 	}
-	return result
+	return issue.NewNested(InvocationError, issue.H{`identifier`: identifier, `name`: name}, 0, cause)
 }
 
 func (c *Client) Metadata(ctx px.Context) (typeSet px.TypeSet, definitions []serviceapi.Definition) {
@@ -130,12 +205,16 @@ func (c *Client) Metadata(ctx px.Context) (typeSet px.TypeSet, definitions []ser
 }
 
 func (c *Client) State(ctx px.Context, identifier string, parameters px.OrderedMap) px.PuppetObject {
-	rq := servicepb.StateRequest{Identifier: identifier, Parameters: ToDataPB(ctx, parameters)}
+	rq := servicepb.StateRequest{Identifier: identifier, Parameters: ToDataPB(ctx, parameters), InvocationId: service.InvocationId(ctx)}
 	rr, err := c.client.State(ctx, &rq)
 	if err != nil {
-		panic(err)
+		panic(rpcError(err, identifier, `state`))
+	}
+	result := FromDataPB(ctx, rr)
+	if eo, ok := result.(serviceapi.ErrorObject); ok {
+		panic(invocationError(eo, identifier, `state`))
 	}
-	return FromDataPB(ctx, rr).(px.PuppetObject)
+	return result.(px.PuppetObject)
 }
 
 // Load  ...
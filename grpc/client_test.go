@@ -0,0 +1,153 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/servicepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type progressAPI struct{}
+
+func (*progressAPI) Work(c px.Context, items int64) string {
+	service.EmitProgress(c, 50, `halfway`, nil)
+	service.EmitProgress(c, 100, `done`, px.SingletonMap(`items`, px.Wrap(c, items)))
+	return `worked`
+}
+
+// unaryServer is a server that doesn't implement streaming invocations
+type unaryServer struct {
+	*Server
+}
+
+func (*unaryServer) InvokeStream(*servicepb.InvokeRequest, servicepb.DefinitionService_InvokeStreamServer) error {
+	return status.Error(codes.Unimplemented, `method InvokeStream not implemented`)
+}
+
+// silentServer is a server that ends its invocation streams without sending a result
+type silentServer struct {
+	*Server
+}
+
+func (*silentServer) InvokeStream(*servicepb.InvokeRequest, servicepb.DefinitionService_InvokeStreamServer) error {
+	return nil
+}
+
+// withClient serves the given server in-process and calls the given function with a client connected to it
+func withClient(srv servicepb.DefinitionServiceServer, f func(*Client)) {
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer()
+	servicepb.RegisterDefinitionServiceServer(gs, srv)
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+
+	conn, err := grpc.Dial(`bufnet`, grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = conn.Close() }()
+	f(&Client{client: servicepb.NewDefinitionServiceClient(conn)})
+}
+
+func progressServer(c px.Context) *Server {
+	sb := service.NewServiceBuilder(c, `My::Service`)
+	sb.RegisterAPI(`My::ProgressApi`, &progressAPI{})
+	return &Server{ctx: c, impl: sb.Server()}
+}
+
+func printProgress(c px.Context) px.Context {
+	return service.WithProgressEmitter(c, func(e *serviceapi.ProgressEvent) {
+		fmt.Println(e.Percent, e.Message, e.Fields)
+	})
+}
+
+func ExampleServer_InvokeStream() {
+	pcore.Do(func(c px.Context) {
+		s := progressServer(c)
+		stream := &recordingStream{ctx: context.Background()}
+		err := s.InvokeStream(&servicepb.InvokeRequest{
+			Identifier: `My::ProgressApi`,
+			Method:     `work`,
+			Arguments:  ToDataPB(c, px.Wrap(c, []interface{}{3}))}, stream)
+		fmt.Println(err)
+		for _, ev := range stream.events {
+			if p := ev.GetProgress(); p != nil {
+				fmt.Println(`progress`, p.Percent, p.Message, FromDataPB(c, p.Fields))
+			}
+			if r := ev.GetResult(); r != nil {
+				fmt.Println(`result`, FromDataPB(c, r))
+			}
+		}
+	})
+
+	// Output:
+	// <nil>
+	// progress 50 halfway <nil>
+	// progress 100 done {'items' => 3}
+	// result worked
+}
+
+func ExampleClient_Invoke_progress() {
+	pcore.Do(func(c px.Context) {
+		withClient(progressServer(c), func(cl *Client) {
+			fmt.Println(cl.Invoke(printProgress(c), `My::ProgressApi`, `work`, px.Wrap(c, 3)))
+		})
+	})
+
+	// Output:
+	// 50 halfway <nil>
+	// 100 done {'items' => 3}
+	// worked
+}
+
+func ExampleClient_Invoke_unaryFallback() {
+	pcore.Do(func(c px.Context) {
+		withClient(&unaryServer{progressServer(c)}, func(cl *Client) {
+			fmt.Println(cl.Invoke(printProgress(c), `My::ProgressApi`, `work`, px.Wrap(c, 3)))
+		})
+	})
+
+	// Output:
+	// worked
+}
+
+func ExampleClient_Invoke_noResult() {
+	pcore.Do(func(c px.Context) {
+		withClient(&silentServer{progressServer(c)}, func(cl *Client) {
+			defer func() { fmt.Println(recover().(issue.Reported).Code()) }()
+			cl.Invoke(printProgress(c), `My::ProgressApi`, `work`, px.Wrap(c, 3))
+		})
+	})
+
+	// Output:
+	// WF_STREAM_WITHOUT_RESULT
+}
+
+// recordingStream is a server side invocation stream that records the events that are sent to it
+type recordingStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*servicepb.InvokeEvent
+}
+
+func (s *recordingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recordingStream) Send(ev *servicepb.InvokeEvent) error {
+	s.events = append(s.events, ev)
+	return nil
+}
@@ -4,12 +4,16 @@ import "github.com/lyraproj/issue/issue"
 
 const (
 	InvocationError       = `WF_INVOCATION_ERROR`
+	InvocationIdInUse     = `WF_INVOCATION_ID_IN_USE`
 	ProcInvocationError   = `WF_PROC_INVOCATION_ERROR`
 	RemoteInvocationError = `WF_REMOTE_INVOCATION_ERROR`
+	StreamWithoutResult   = `WF_STREAM_WITHOUT_RESULT`
 )
 
 func init() {
 	issue.Hard(RemoteInvocationError, `Failed to invoke method %{executable}#%{identifier}/%{name}() on host %{host}`)
 	issue.Hard(ProcInvocationError, `Failed to invoke method %{executable}#%{identifier}/%{name}()`)
 	issue.Hard(InvocationError, `Failed to invoke method %{identifier}/%{name}()`)
+	issue.Hard(StreamWithoutResult, `the invocation stream of %{identifier}/%{name}() ended without a result`)
+	issue.Hard(InvocationIdInUse, `an invocation with id '%{invocationId}' is already in progress`)
 }
@@ -3,6 +3,7 @@ package grpc
 import (
 	"fmt"
 	"net/rpc"
+	"sync"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
@@ -14,6 +15,7 @@ import (
 	"github.com/lyraproj/pcore/serialization"
 	"github.com/lyraproj/pcore/threadlocal"
 	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/service"
 	"github.com/lyraproj/servicesdk/serviceapi"
 	"github.com/lyraproj/servicesdk/servicepb"
 	"golang.org/x/net/context"
@@ -21,8 +23,10 @@ import (
 )
 
 type Server struct {
-	ctx  px.Context
-	impl serviceapi.Service
+	ctx      px.Context
+	impl     serviceapi.Service
+	lock     sync.Mutex
+	inFlight map[string]context.CancelFunc
 }
 
 func (s *Server) Server(*plugin.MuxBroker) (interface{}, error) {
@@ -42,8 +46,10 @@ func (s *Server) GRPCClient(context.Context, *plugin.GRPCBroker, *grpc.ClientCon
 	return nil, fmt.Errorf(`%T has no client implementation for rpc`, s)
 }
 
-func (s *Server) Do(doer func(c px.Context)) (publicErr *datapb.Data, err error) {
-	c := s.ctx.Fork()
+// Do calls the given doer with a fork of the server context. The deadline and cancellation of the
+// forked context are determined by the given Go context, i.e. the context of the gRPC request.
+func (s *Server) Do(ctx context.Context, doer func(c px.Context)) (publicErr *datapb.Data, err error) {
+	c := service.WithContext(s.ctx.Fork(), ctx)
 	defer func() {
 		if x := recover(); x != nil {
 			if e, ok := x.(error); ok {
@@ -62,16 +68,98 @@ func (s *Server) Do(doer func(c px.Context)) (publicErr *datapb.Data, err error)
 	return nil, nil
 }
 
-func (s *Server) Identity(context.Context, *servicepb.EmptyRequest) (result *datapb.Data, err error) {
-	_, err = s.Do(func(c px.Context) {
+// track registers a cancel function for the call with the given invocation id in the registry of calls that are
+// in flight. The returned function must be called when the call ends. Calls without an invocation id are not tracked.
+func (s *Server) track(ctx context.Context, invocationId string) (context.Context, func(), error) {
+	if invocationId == `` {
+		return ctx, func() {}, nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, found := s.inFlight[invocationId]; found {
+		return nil, nil, issue.NewReported(InvocationIdInUse, issue.SeverityError, issue.H{`invocationId`: invocationId}, nil)
+	}
+	if s.inFlight == nil {
+		s.inFlight = make(map[string]context.CancelFunc)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.inFlight[invocationId] = cancel
+	return ctx, func() {
+		s.lock.Lock()
+		delete(s.inFlight, invocationId)
+		s.lock.Unlock()
+		cancel()
+	}, nil
+}
+
+// Cancel aborts the Invoke or State call with the given invocation id
+func (s *Server) Cancel(_ context.Context, r *servicepb.CancelRequest) (*servicepb.CancelResponse, error) {
+	s.lock.Lock()
+	cancel, found := s.inFlight[r.InvocationId]
+	s.lock.Unlock()
+	if found {
+		cancel()
+	}
+	return &servicepb.CancelResponse{Canceled: found}, nil
+}
+
+func (s *Server) Identity(ctx context.Context, _ *servicepb.EmptyRequest) (result *datapb.Data, err error) {
+	_, err = s.Do(ctx, func(c px.Context) {
 		result = ToDataPB(c, s.impl.Identifier(c))
 	})
 	return
 }
 
-func (s *Server) Invoke(_ context.Context, r *servicepb.InvokeRequest) (result *datapb.Data, err error) {
+func (s *Server) Invoke(ctx context.Context, r *servicepb.InvokeRequest) (result *datapb.Data, err error) {
+	return s.invoke(ctx, r, nil)
+}
+
+// InvokeStream performs an invocation during which the handler can report progress. Each progress
+// report is sent as an event on the given stream. The final event contains the result.
+func (s *Server) InvokeStream(r *servicepb.InvokeRequest, stream servicepb.DefinitionService_InvokeStreamServer) error {
+	var lock sync.Mutex
+	ended := false
+	result, err := s.invoke(stream.Context(), r, func(c px.Context, e *serviceapi.ProgressEvent) {
+		ev := &servicepb.ProgressEvent{Percent: e.Percent, Message: e.Message}
+		if e.Fields != nil {
+			ev.Fields = ToDataPB(c, e.Fields)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if !ended {
+			// A failure to send progress must not affect the invocation
+			_ = stream.Send(&servicepb.InvokeEvent{Progress: ev})
+		}
+	})
+
+	// The handler might still be running when the invocation was aborted so further
+	// progress must be discarded
+	lock.Lock()
+	ended = true
+	lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return stream.Send(&servicepb.InvokeEvent{Result: result})
+}
+
+func (s *Server) invoke(ctx context.Context, r *servicepb.InvokeRequest, emit func(px.Context, *serviceapi.ProgressEvent)) (result *datapb.Data, err error) {
+	ctx, done, err := s.track(ctx, r.InvocationId)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	var publicErr *datapb.Data
-	publicErr, err = s.Do(func(c px.Context) {
+	publicErr, err = s.Do(ctx, func(c px.Context) {
+		if r.InvocationId != `` {
+			c = service.WithInvocationId(c, r.InvocationId)
+		}
+		if emit != nil {
+			ec := c
+			c = service.WithProgressEmitter(c, func(e *serviceapi.ProgressEvent) { emit(ec, e) })
+		}
 		wrappedArgs := FromDataPB(c, r.Arguments)
 		arguments := wrappedArgs.(*types.Array).AppendTo([]px.Value{})
 		rrr := s.impl.Invoke(
@@ -88,8 +176,8 @@ func (s *Server) Invoke(_ context.Context, r *servicepb.InvokeRequest) (result *
 	return
 }
 
-func (s *Server) Metadata(_ context.Context, r *servicepb.EmptyRequest) (result *servicepb.MetadataResponse, err error) {
-	_, err = s.Do(func(c px.Context) {
+func (s *Server) Metadata(ctx context.Context, r *servicepb.EmptyRequest) (result *servicepb.MetadataResponse, err error) {
+	_, err = s.Do(ctx, func(c px.Context) {
 		ts, ds := s.impl.Metadata(c)
 		vs := make([]px.Value, len(ds))
 		for i, d := range ds {
@@ -100,8 +188,17 @@ func (s *Server) Metadata(_ context.Context, r *servicepb.EmptyRequest) (result
 	return
 }
 
-func (s *Server) State(_ context.Context, r *servicepb.StateRequest) (result *datapb.Data, err error) {
-	_, err = s.Do(func(c px.Context) {
+func (s *Server) State(ctx context.Context, r *servicepb.StateRequest) (result *datapb.Data, err error) {
+	ctx, done, err := s.track(ctx, r.InvocationId)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	_, err = s.Do(ctx, func(c px.Context) {
+		if r.InvocationId != `` {
+			c = service.WithInvocationId(c, r.InvocationId)
+		}
 		result = ToDataPB(c, s.impl.State(c, r.Identifier, FromDataPB(c, r.Parameters).(px.OrderedMap)))
 	})
 	return
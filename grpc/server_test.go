@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/servicepb"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+type slowAPI struct{}
+
+func (*slowAPI) Sleep(millis int64) string {
+	time.Sleep(time.Duration(millis) * time.Millisecond)
+	return `awake`
+}
+
+func ExampleServer_Cancel() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterAPI(`My::SlowApi`, &slowAPI{})
+		s := &Server{ctx: c, impl: sb.Server()}
+
+		rc := make(chan px.Value)
+		go func() {
+			rr, _ := s.Invoke(context.Background(), &servicepb.InvokeRequest{
+				Identifier:   `My::SlowApi`,
+				Method:       `sleep`,
+				Arguments:    ToDataPB(c, types.WrapValues([]px.Value{px.Wrap(c, 10000)})),
+				InvocationId: `the-id`})
+			rc <- FromDataPB(c, rr)
+		}()
+
+		for {
+			cr, _ := s.Cancel(context.Background(), &servicepb.CancelRequest{InvocationId: `the-id`})
+			if cr.Canceled {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if eo, ok := (<-rc).(serviceapi.ErrorObject); ok {
+			fmt.Println(eo.Kind(), eo.IssueCode())
+		}
+		cr, _ := s.Cancel(context.Background(), &servicepb.CancelRequest{InvocationId: `the-id`})
+		fmt.Println(cr.Canceled)
+	})
+
+	// Output:
+	// CANCELED WF_INVOCATION_CANCELED
+	// false
+}
+
+func ExampleServer_Metadata_when() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep(wf.NewCall(c, func(b wf.CallBuilder) {
+			b.Name(`My::Deploy`)
+			b.When(`!(env in ['test', 'dev']) and vpc.tags.approved`)
+			b.Parameters(b.Parameter(`env`, `String`), b.Parameter(`vpc`, `Hash`))
+			b.CallTo(`My::Provision`)
+		}))
+		s := &Server{ctx: c, impl: sb.Server()}
+
+		mr, _ := s.Metadata(context.Background(), &servicepb.EmptyRequest{})
+		def := FromDataPB(c, mr.Definitions).(px.List).At(0).(serviceapi.Definition)
+		when := def.Properties().Get5(`when`, px.Undef)
+		fmt.Println(when.PType().Name())
+		fmt.Println(when)
+
+		cond := when.(wf.Condition)
+		fmt.Println(cond.IsTrue(px.Wrap(c, map[string]interface{}{
+			`env`: `prod`, `vpc`: map[string]interface{}{`tags`: map[string]interface{}{`approved`: true}}}).(px.OrderedMap)))
+		fmt.Println(cond.IsTrue(px.Wrap(c, map[string]interface{}{
+			`env`: `dev`, `vpc`: map[string]interface{}{`tags`: map[string]interface{}{`approved`: true}}}).(px.OrderedMap)))
+	})
+
+	// Output:
+	// Lyra::AndCondition
+	// !(env in ['test', 'dev']) and vpc.tags.approved
+	// true
+	// false
+}
@@ -0,0 +1,153 @@
+// Package identity provides implementations of the serviceapi.Identity API. The implementations can be
+// registered as an API of a service using service.Builder.RegisterAPI.
+package identity
+
+import (
+	"sync"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/serviceapi"
+)
+
+// identity implements serviceapi.Identity.
+//
+// An entry that is associated, or looked up, is touched, i.e. it becomes a member of the current era. A sweep
+// marks entries that haven't been touched in the current era as garbage. Removing an entry also marks it as
+// garbage. Garbage entries are not found by lookups or searches but are listed by Garbage until they are purged.
+type identity struct {
+	lock    sync.Mutex
+	store   *store
+	journal *Journal
+}
+
+// NewMemory returns an Identity that keeps all its mappings in memory
+func NewMemory() serviceapi.Identity {
+	return &identity{store: newStore()}
+}
+
+// do applies the given record to the store. A journaled record is written before it is applied. The caller
+// must hold the lock.
+func (i *identity) do(r *record) {
+	if i.journal == nil {
+		i.store.apply(r)
+		return
+	}
+	if err := i.journal.write(r); err != nil {
+		panic(px.Error(JournalWriteError, issue.H{`path`: i.journal.path, `detail`: err.Error()}))
+	}
+	i.store.apply(r)
+	i.journal.compactIfNeeded()
+}
+
+func (i *identity) BumpEra(px.Context) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.do(&record{Op: opEra, Era: i.store.era + 1})
+}
+
+func (i *identity) AddReference(_ px.Context, internalId, otherId string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.do(&record{Op: opReference, Args: []string{internalId, otherId}})
+}
+
+func (i *identity) Associate(_ px.Context, internalId, externalId string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.do(&record{Op: opAssociate, Args: []string{internalId, externalId}})
+}
+
+func (i *identity) GetExternal(_ px.Context, internalId string) (string, bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if e, ok := i.store.live(internalId); ok {
+		i.touch(internalId, e)
+		return e.externalId, true
+	}
+	return ``, false
+}
+
+func (i *identity) GetInternal(_ px.Context, externalId string) (string, bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if internalId, ok := i.store.internals[externalId]; ok {
+		if e, ok := i.store.live(internalId); ok {
+			i.touch(internalId, e)
+			return internalId, true
+		}
+	}
+	return ``, false
+}
+
+func (i *identity) touch(internalId string, e *entry) {
+	if e.era < i.store.era {
+		i.do(&record{Op: opTouch, Args: []string{internalId}})
+	}
+}
+
+func (i *identity) PurgeExternal(_ px.Context, externalId string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if internalId, ok := i.store.internals[externalId]; ok {
+		i.do(&record{Op: opPurge, Args: []string{internalId}})
+	}
+}
+
+func (i *identity) PurgeInternal(_ px.Context, internalId string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if _, ok := i.store.entries[internalId]; ok {
+		i.do(&record{Op: opPurge, Args: []string{internalId}})
+	}
+}
+
+func (i *identity) PurgeReferences(_ px.Context, internalIdPrefix string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.do(&record{Op: opPurgeReferences, Args: []string{internalIdPrefix}})
+}
+
+func (i *identity) RemoveExternal(_ px.Context, externalId string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if internalId, ok := i.store.internals[externalId]; ok {
+		i.do(&record{Op: opRemove, Args: []string{internalId}})
+	}
+}
+
+func (i *identity) RemoveInternal(_ px.Context, internalId string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if _, ok := i.store.entries[internalId]; ok {
+		i.do(&record{Op: opRemove, Args: []string{internalId}})
+	}
+}
+
+func (i *identity) Search(_ px.Context, internalIdPrefix string) px.List {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.tuples(i.store.selectIds(internalIdPrefix, false))
+}
+
+func (i *identity) Sweep(_ px.Context, internalIdPrefix string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.do(&record{Op: opSweep, Args: []string{internalIdPrefix}})
+}
+
+func (i *identity) Garbage(_ px.Context, internalIdPrefix string) px.List {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.tuples(i.store.selectIds(internalIdPrefix, true))
+}
+
+// tuples returns a list of [internalId, externalId] tuples for the given internal IDs
+func (i *identity) tuples(ids []string) px.List {
+	ts := make([]px.Value, len(ids))
+	for x, id := range ids {
+		ts[x] = types.WrapStrings([]string{id, i.store.entries[id].externalId})
+	}
+	return types.WrapValues(ts)
+}
@@ -0,0 +1,154 @@
+package identity_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/identity"
+	"github.com/lyraproj/servicesdk/service"
+)
+
+func ExampleNewMemory() {
+	pcore.Do(func(c px.Context) {
+		id := identity.NewMemory()
+		id.Associate(c, `wf:a`, `ext-a`)
+		id.Associate(c, `wf:b`, `ext-b`)
+		id.Associate(c, `wf:c`, `ext-c`)
+		id.AddReference(c, `wf:a`, `wf:c`)
+
+		id.BumpEra(c)
+		id.GetExternal(c, `wf:a`)
+		id.Sweep(c, `wf:`)
+
+		fmt.Println(id.Search(c, `wf:`))
+		fmt.Println(id.Garbage(c, `wf:`))
+		fmt.Println(id.GetInternal(c, `ext-b`))
+
+		id.PurgeExternal(c, `ext-b`)
+		fmt.Println(id.Garbage(c, `wf:`))
+	})
+
+	// Output:
+	// [['wf:a', 'ext-a'], ['wf:c', 'ext-c']]
+	// [['wf:b', 'ext-b']]
+	//  false
+	// []
+}
+
+func ExampleOpenJournal() {
+	dir, _ := ioutil.TempDir(``, `identity`)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, `identity.journal`)
+
+	pcore.Do(func(c px.Context) {
+		j, err := identity.OpenJournal(path)
+		if err != nil {
+			panic(err)
+		}
+		id := j.Identity()
+		id.Associate(c, `wf:a`, `ext-a`)
+		id.Associate(c, `wf:b`, `ext-b`)
+		id.BumpEra(c)
+		id.GetExternal(c, `wf:b`)
+		id.RemoveInternal(c, `wf:a`)
+		_ = j.Close()
+
+		// Simulate a crash in the middle of a write
+		f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		_, _ = f.WriteString(`{"op":"associate","args":["wf:c"`)
+		_ = f.Close()
+
+		j, err = identity.OpenJournal(path)
+		if err != nil {
+			panic(err)
+		}
+		id = j.Identity()
+		id.Sweep(c, `wf:`)
+		fmt.Println(id.Search(c, `wf:`))
+		fmt.Println(id.Garbage(c, `wf:`))
+		_ = j.Close()
+	})
+
+	// Output:
+	// [['wf:b', 'ext-b']]
+	// [['wf:a', 'ext-a']]
+}
+
+// lines returns the number of complete lines in the file at the given path
+func lines(path string) int {
+	bs, _ := ioutil.ReadFile(path)
+	return strings.Count(string(bs), "\n")
+}
+
+func ExampleJournal_Compact() {
+	dir, _ := ioutil.TempDir(``, `identity`)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, `identity.journal`)
+
+	pcore.Do(func(c px.Context) {
+		j, err := identity.OpenJournal(path)
+		if err != nil {
+			panic(err)
+		}
+		j.CompactionThreshold = 10
+		id := j.Identity()
+		id.Associate(c, `wf:a`, `ext-a`)
+		id.AddReference(c, `wf:a`, `wf:b`)
+		for i := 0; i < 5; i++ {
+			id.Associate(c, `wf:b`, fmt.Sprint(`ext-b`, i))
+		}
+		// The era record written when the journal was opened followed by seven appended records
+		fmt.Println(lines(path))
+		for i := 5; i < 10; i++ {
+			id.Associate(c, `wf:b`, fmt.Sprint(`ext-b`, i))
+		}
+		// The eleventh appended record exceeds the threshold and compacts the journal into one era
+		// record, two entries, and one reference. One record is appended after that.
+		fmt.Println(lines(path))
+		_, err = os.Stat(path + `.tmp`)
+		fmt.Println(os.IsNotExist(err))
+		_ = j.Close()
+
+		// Simulate a crash in the middle of a write after the compaction
+		f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		_, _ = f.WriteString(`{"op":"associate","args":["wf:c"`)
+		_ = f.Close()
+
+		j, err = identity.OpenJournal(path)
+		if err != nil {
+			panic(err)
+		}
+		id = j.Identity()
+		fmt.Println(id.Search(c, `wf:`))
+		// The truncated line is dropped by the compaction when the journal is opened
+		bs, _ := ioutil.ReadFile(path)
+		fmt.Println(lines(path), strings.Contains(string(bs), `wf:c`))
+		_ = j.Close()
+	})
+
+	// Output:
+	// 8
+	// 5
+	// true
+	// [['wf:a', 'ext-a'], ['wf:b', 'ext-b9']]
+	// 4 false
+}
+
+func ExampleNewMemory_api() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterAPI(`My::Identity`, identity.NewMemory())
+		s := sb.Server()
+
+		s.Invoke(c, `My::Identity`, `associate`, px.Wrap(c, `wf:a`), px.Wrap(c, `ext-a`))
+		fmt.Println(s.Invoke(c, `My::Identity`, `getExternal`, px.Wrap(c, `wf:a`)))
+	})
+
+	// Output:
+	// ['ext-a', true]
+}
@@ -0,0 +1,13 @@
+package identity
+
+import "github.com/lyraproj/issue/issue"
+
+const (
+	JournalCorrupt    = `WF_IDENTITY_JOURNAL_CORRUPT`
+	JournalWriteError = `WF_IDENTITY_JOURNAL_WRITE_ERROR`
+)
+
+func init() {
+	issue.Hard(JournalCorrupt, `identity journal %{path} is corrupt at line %{line}`)
+	issue.Hard(JournalWriteError, `unable to write identity journal %{path}: %{detail}`)
+}
@@ -0,0 +1,195 @@
+package identity
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/serviceapi"
+)
+
+// DefaultCompactionThreshold is the default number of records that can be appended to a journal before
+// it is considered for compaction
+const DefaultCompactionThreshold = 1000
+
+// Journal is a file that records all changes made to an Identity. Each change is appended to the file and
+// synced to disk before the change is considered done, so no completed change is lost if the process
+// crashes. A change that was in progress during a crash is ignored when the journal is opened again.
+//
+// The journal is compacted when it is opened and when the number of appended records exceed both the
+// compaction threshold and twice the number of records needed to represent the current state. Compaction
+// replaces the journal with a snapshot of the current state in an atomic rename.
+type Journal struct {
+	path                string
+	file                journalFile
+	appended            int
+	CompactionThreshold int
+	identity            *identity
+}
+
+// journalFile is the part of an *os.File that is used when appending records to a journal
+type journalFile interface {
+	io.WriteSeeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// OpenJournal opens the journal at the given path, or creates it if it doesn't exist, and recreates the
+// Identity from its records.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, CompactionThreshold: DefaultCompactionThreshold}
+	s := newStore()
+	if err := j.replay(s); err != nil {
+		return nil, err
+	}
+	j.identity = &identity{store: s, journal: j}
+	if err := j.Compact(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Identity returns the Identity that is recorded by this journal
+func (j *Journal) Identity() serviceapi.Identity {
+	return j.identity
+}
+
+// Close closes the journal file. The Identity must not be changed once the journal is closed.
+func (j *Journal) Close() error {
+	j.identity.lock.Lock()
+	defer j.identity.lock.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Compact replaces the journal with a snapshot of the current state of the Identity
+func (j *Journal) Compact() error {
+	j.identity.lock.Lock()
+	defer j.identity.lock.Unlock()
+	return j.compact()
+}
+
+func (j *Journal) replay(s *store) error {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	rd := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		bs, err := rd.ReadBytes('\n')
+		if err == io.EOF {
+			// A last line without newline is the remains of a write that was interrupted
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r := &record{}
+		if err = json.Unmarshal(bs, r); err != nil {
+			return px.Error(JournalCorrupt, issue.H{`path`: j.path, `line`: line})
+		}
+		s.apply(r)
+	}
+}
+
+func (j *Journal) compact() error {
+	if j.file != nil {
+		if err := j.file.Close(); err != nil {
+			return err
+		}
+		j.file = nil
+	}
+
+	buf := bytes.NewBufferString(``)
+	enc := json.NewEncoder(buf)
+	for _, r := range j.identity.store.snapshot() {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	tmp := j.path + `.tmp`
+	if err := writeSynced(tmp, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+	if dir, err := os.Open(filepath.Dir(j.path)); err == nil {
+		// Persist the rename. Not all platforms support syncing a directory so errors are ignored
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	j.file = f
+	j.appended = 0
+	return nil
+}
+
+// write appends the given record to the journal. A record that isn't appended in full is truncated away so
+// that the records that are appended after it can be replayed. The caller must hold the identity lock.
+func (j *Journal) write(r *record) error {
+	if j.file == nil {
+		return os.ErrClosed
+	}
+	bs, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	offset, err := j.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err = j.file.Write(append(bs, '\n')); err == nil {
+		err = j.file.Sync()
+	}
+	if err != nil {
+		_ = j.file.Truncate(offset)
+		return err
+	}
+	j.appended++
+	return nil
+}
+
+// compactIfNeeded compacts the journal when the number of appended records is large compared to the size
+// of the current state. The caller must hold the identity lock.
+func (j *Journal) compactIfNeeded() {
+	if j.appended > j.CompactionThreshold && j.appended > 2*j.identity.store.size() {
+		if err := j.compact(); err != nil {
+			panic(px.Error(JournalWriteError, issue.H{`path`: j.path, `detail`: err.Error()}))
+		}
+	}
+}
+
+func writeSynced(path string, bs []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(bs); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+)
+
+// fullFile is a journal file that writes the first few bytes of a record and then reports that the disk is full
+type fullFile struct {
+	journalFile
+}
+
+func (f fullFile) Write(bs []byte) (int, error) {
+	n, _ := f.journalFile.Write(bs[:5])
+	return n, errors.New(`no space left on device`)
+}
+
+func ExampleJournal_write() {
+	dir, _ := ioutil.TempDir(``, `identity`)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, `identity.journal`)
+
+	pcore.Do(func(c px.Context) {
+		j, err := OpenJournal(path)
+		if err != nil {
+			panic(err)
+		}
+		id := j.Identity()
+		id.Associate(c, `wf:a`, `ext-a`)
+
+		f := j.file
+		j.file = fullFile{f}
+		func() {
+			defer func() {
+				r := recover().(issue.Reported)
+				fmt.Println(r.Code(), r.Argument(`detail`))
+			}()
+			id.Associate(c, `wf:b`, `ext-b`)
+		}()
+		j.file = f
+		id.Associate(c, `wf:c`, `ext-c`)
+		_ = j.Close()
+
+		j, err = OpenJournal(path)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(j.Identity().Search(c, `wf:`))
+		_ = j.Close()
+	})
+
+	// Output:
+	// WF_IDENTITY_JOURNAL_WRITE_ERROR no space left on device
+	// [['wf:a', 'ext-a'], ['wf:c', 'ext-c']]
+}
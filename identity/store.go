@@ -0,0 +1,188 @@
+package identity
+
+import (
+	"sort"
+	"strings"
+)
+
+// Record operations. Each change to the store is described by a record so that the same
+// changes can be applied to the store in memory and written to a journal.
+const (
+	opAssociate       = `associate`
+	opEntry           = `entry`
+	opEra             = `era`
+	opPurge           = `purge`
+	opPurgeReferences = `purgeReferences`
+	opReference       = `reference`
+	opRemove          = `remove`
+	opSweep           = `sweep`
+	opTouch           = `touch`
+)
+
+type record struct {
+	Op      string   `json:"op"`
+	Args    []string `json:"args,omitempty"`
+	Era     int      `json:"era,omitempty"`
+	Garbage bool     `json:"garbage,omitempty"`
+}
+
+type entry struct {
+	externalId string
+	era        int
+	garbage    bool
+}
+
+// store keeps the mappings between internal and external IDs together with the references between
+// internal IDs.
+type store struct {
+	era        int
+	entries    map[string]*entry
+	internals  map[string]string
+	references map[string]map[string]bool
+}
+
+func newStore() *store {
+	return &store{
+		entries:    make(map[string]*entry),
+		internals:  make(map[string]string),
+		references: make(map[string]map[string]bool)}
+}
+
+func (s *store) apply(r *record) {
+	switch r.Op {
+	case opEra:
+		s.era = r.Era
+	case opAssociate:
+		s.associate(r.Args[0], r.Args[1], s.era, false)
+	case opEntry:
+		s.associate(r.Args[0], r.Args[1], r.Era, r.Garbage)
+	case opReference:
+		refs, ok := s.references[r.Args[0]]
+		if !ok {
+			refs = make(map[string]bool)
+			s.references[r.Args[0]] = refs
+		}
+		refs[r.Args[1]] = true
+	case opTouch:
+		if e, ok := s.entries[r.Args[0]]; ok {
+			e.era = s.era
+		}
+	case opRemove:
+		if e, ok := s.entries[r.Args[0]]; ok {
+			e.garbage = true
+		}
+	case opPurge:
+		s.purge(r.Args[0])
+	case opPurgeReferences:
+		for id := range s.references {
+			if strings.HasPrefix(id, r.Args[0]) {
+				delete(s.references, id)
+			}
+		}
+	case opSweep:
+		s.sweep(r.Args[0])
+	}
+}
+
+func (s *store) associate(internalId, externalId string, era int, garbage bool) {
+	s.removeEntry(internalId)
+	if oldId, ok := s.internals[externalId]; ok {
+		s.removeEntry(oldId)
+	}
+	s.entries[internalId] = &entry{externalId, era, garbage}
+	s.internals[externalId] = internalId
+}
+
+func (s *store) removeEntry(internalId string) {
+	if e, ok := s.entries[internalId]; ok {
+		delete(s.internals, e.externalId)
+		delete(s.entries, internalId)
+	}
+}
+
+func (s *store) purge(internalId string) {
+	s.removeEntry(internalId)
+	delete(s.references, internalId)
+}
+
+// sweep marks all entries with the given prefix that have not been touched in the current era as garbage, unless
+// they are referenced from an entry that isn't garbage.
+func (s *store) sweep(prefix string) {
+	for id, e := range s.entries {
+		if e.era < s.era && strings.HasPrefix(id, prefix) {
+			e.garbage = true
+		}
+	}
+	for revived := true; revived; {
+		revived = false
+		for id, refs := range s.references {
+			if e, ok := s.entries[id]; ok && e.garbage {
+				continue
+			}
+			for ref := range refs {
+				if re, ok := s.entries[ref]; ok && re.garbage && strings.HasPrefix(ref, prefix) {
+					re.garbage = false
+					revived = true
+				}
+			}
+		}
+	}
+}
+
+func (s *store) live(internalId string) (*entry, bool) {
+	e, ok := s.entries[internalId]
+	if ok && e.garbage {
+		ok = false
+	}
+	return e, ok
+}
+
+// selectIds returns the sorted internal IDs of all entries that have the given prefix and garbage state
+func (s *store) selectIds(prefix string, garbage bool) []string {
+	ids := make([]string, 0)
+	for id, e := range s.entries {
+		if e.garbage == garbage && strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// snapshot returns the records needed to recreate the current state of the store
+func (s *store) snapshot() []*record {
+	rs := []*record{{Op: opEra, Era: s.era}}
+	ids := make([]string, 0, len(s.entries))
+	for id := range s.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		e := s.entries[id]
+		rs = append(rs, &record{Op: opEntry, Args: []string{id, e.externalId}, Era: e.era, Garbage: e.garbage})
+	}
+	ids = ids[:0]
+	for id := range s.references {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		refs := make([]string, 0, len(s.references[id]))
+		for ref := range s.references[id] {
+			refs = append(refs, ref)
+		}
+		sort.Strings(refs)
+		for _, ref := range refs {
+			rs = append(rs, &record{Op: opReference, Args: []string{id, ref}})
+		}
+	}
+	return rs
+}
+
+func (s *store) size() int {
+	n := len(s.entries) + 1
+	for _, refs := range s.references {
+		n += len(refs)
+	}
+	return n
+}
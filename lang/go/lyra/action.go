@@ -3,6 +3,7 @@ package lyra
 import (
 	"io"
 	"reflect"
+	"time"
 
 	"github.com/lyraproj/servicesdk/serviceapi"
 
@@ -26,6 +27,20 @@ type Action struct {
 	// struct, or a pointer to a struct. If two values are returned, the first value must be struct or a pointer to a
 	// struct and the second must be an error. The exported fields of a returned struct becomes the returns of the action.
 	Do interface{}
+
+	// Undo is an optional function that compensates for the work done by Do, e.g. when a later step in the
+	// workflow fails.
+	//
+	// The function can take one optional parameter which must be a struct or pointer to a struct. The exported fields of
+	// that struct are assigned from the parameters and the returns of the Do invocation that is compensated for. The
+	// function can return zero values or an error.
+	Undo interface{}
+
+	// Retry is an optional policy that controls how the action is retried when it fails
+	Retry *wf.RetryPolicy
+
+	// Timeout is the maximum duration of one invocation of Do. Zero means no limit
+	Timeout time.Duration
 }
 
 func (a *Action) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
@@ -76,48 +91,108 @@ func (a *Action) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
 
 	ga := &goAction{returnsError: returnsError, doer: fv}
 	as := wf.MakeAction(n, loc, wf.Parse(a.When), parameters, returns, ga)
+	if a.Undo != nil {
+		ga.undoer = a.resolveUndo(c, n, parameters, returns)
+		wf.WithUndo(as, `undo`)
+	}
+	wf.WithTimeout(wf.WithRetry(as, a.Retry), a.Timeout)
 	ga.action = as
 	return as
 }
 
+// resolveUndo validates the Undo function of the action and returns its reflected value
+func (a *Action) resolveUndo(c px.Context, n string, parameters, returns []serviceapi.Parameter) reflect.Value {
+	fv := reflect.ValueOf(a.Undo)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		panic(px.Error(NotActionFunction, issue.H{`name`: n, `type`: ft.String()}))
+	}
+
+	inc := ft.NumIn()
+	idx := 0
+	if inc > 0 && ft.In(0).AssignableTo(px.ContextType) {
+		inc--
+		idx++
+	}
+	if ft.IsVariadic() || inc > 1 {
+		panic(badFunction(n, ft))
+	}
+	switch ft.NumOut() {
+	case 0:
+	case 1:
+		if !ft.Out(0).AssignableTo(errorInterface) {
+			panic(badFunction(n, ft))
+		}
+	default:
+		panic(badFunction(n, ft))
+	}
+
+	if inc == 1 {
+		known := make(map[string]bool, len(parameters)+len(returns))
+		for _, p := range append(append([]serviceapi.Parameter{}, parameters...), returns...) {
+			known[p.Name()] = true
+		}
+		for _, p := range paramsFromStruct(c, ft.In(idx), nil) {
+			if !known[p.Name()] {
+				panic(px.Error(UnknownUndoParameter, issue.H{`name`: n, `parameter`: p.Name()}))
+			}
+		}
+	}
+	return fv
+}
+
 type goAction struct {
 	action       wf.Action
 	doer         reflect.Value
+	undoer       reflect.Value
 	returnsError bool
 }
 
 var goActionType px.ObjectType
+var goUndoableActionType px.ObjectType
 
 func init() {
 	goActionType = px.NewGoObjectType(`Lyra::Action`, reflect.TypeOf(&goAction{}), `{
 		functions => {
       do => Callable[[Hash[String,RichData]], Hash[String,RichData]]
     }
+  }`)
+
+	goUndoableActionType = px.NewObjectType(`Lyra::UndoableAction`, `Lyra::Action{
+    functions => {
+      undo => Callable[[Hash[String,RichData]], Hash[String,RichData]]
+    }
   }`)
 }
 
 // Call checks if the method is 'do' and then converts the single argument OrderedMap into the go struct required by the
 // go function, calls the function, and then converts the returned go struct into an OrderedMap which is returned.
-// Call will return nil, false for any other method than 'do'
+// The 'undo' method of an action that has an Undo function is handled the same way but always returns an empty
+// OrderedMap. Call will return nil, false for any other method.
 func (a *goAction) Call(ctx px.Context, method px.ObjFunc, args []px.Value, block px.Lambda) (px.Value, bool) {
-	if method.Name() != `do` {
-		return nil, false
+	switch method.Name() {
+	case `do`:
+		return a.do(ctx, args[0].(px.OrderedMap)), true
+	case `undo`:
+		if a.undoer.IsValid() {
+			a.undo(ctx, args[0].(px.OrderedMap))
+			return px.EmptyMap, true
+		}
 	}
-	fvType := a.doer.Type()
+	return nil, false
+}
 
-	parameters := args[0].(px.OrderedMap)
-	params := make([]reflect.Value, 0)
-	if fvType.NumIn() > 0 {
-		inType := fvType.In(0)
-		if inType.AssignableTo(px.ContextType) {
-			params = append(params, reflect.ValueOf(ctx))
-			if fvType.NumIn() > 1 {
-				params = append(params, reflectParameters(ctx, fvType.In(1), parameters))
-			}
-		} else {
-			params = append(params, reflectParameters(ctx, inType, parameters))
-		}
+func (a *goAction) undo(ctx px.Context, parameters px.OrderedMap) {
+	defer a.amendError()
+
+	result := a.undoer.Call(callParameters(ctx, a.undoer.Type(), parameters))
+	if len(result) == 1 && !result[0].IsNil() {
+		panic(result[0].Interface())
 	}
+}
+
+func (a *goAction) do(ctx px.Context, parameters px.OrderedMap) px.Value {
+	params := callParameters(ctx, a.doer.Type(), parameters)
 
 	defer a.amendError()
 
@@ -125,9 +200,10 @@ func (a *goAction) Call(ctx px.Context, method px.ObjFunc, args []px.Value, bloc
 	var re, rs reflect.Value
 	switch len(result) {
 	case 1:
-		rs = result[0]
 		if a.returnsError {
 			re = result[0]
+		} else {
+			rs = result[0]
 		}
 	case 2:
 		rs = result[0]
@@ -136,12 +212,12 @@ func (a *goAction) Call(ctx px.Context, method px.ObjFunc, args []px.Value, bloc
 		}
 	}
 
-	if re.IsValid() && re.Type().AssignableTo(errorInterface) {
-		panic(rs.Interface())
+	if re.IsValid() && !re.IsNil() {
+		panic(re.Interface())
 	}
 
 	if !rs.IsValid() {
-		return px.EmptyMap, true
+		return px.EmptyMap
 	}
 
 	rt := rs.Type()
@@ -164,7 +240,25 @@ func (a *goAction) Call(ctx px.Context, method px.ObjFunc, args []px.Value, bloc
 			entries[i] = types.WrapHashEntry2(n, px.Undef)
 		}
 	}
-	return types.WrapHash(entries), true
+	return types.WrapHash(entries)
+}
+
+// callParameters returns the arguments for a call to a go function of the given type. The function can take an
+// optional context followed by an optional struct that is created from the given parameters.
+func callParameters(ctx px.Context, fvType reflect.Type, parameters px.OrderedMap) []reflect.Value {
+	params := make([]reflect.Value, 0)
+	if fvType.NumIn() > 0 {
+		inType := fvType.In(0)
+		if inType.AssignableTo(px.ContextType) {
+			params = append(params, reflect.ValueOf(ctx))
+			if fvType.NumIn() > 1 {
+				params = append(params, reflectParameters(ctx, fvType.In(1), parameters))
+			}
+		} else {
+			params = append(params, reflectParameters(ctx, inType, parameters))
+		}
+	}
+	return params
 }
 
 func (a *goAction) String() string {
@@ -179,7 +273,11 @@ func (a *goAction) ToString(bld io.Writer, format px.FormatContext, g px.RDetect
 	types.ObjectToString(a, format, bld, g)
 }
 
+// PType returns Lyra::UndoableAction when the action has an Undo function and Lyra::Action otherwise
 func (a *goAction) PType() px.Type {
+	if a.undoer.IsValid() {
+		return goUndoableActionType
+	}
 	return goActionType
 }
 
@@ -0,0 +1,33 @@
+package lyra_test
+
+import (
+	"fmt"
+
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/lang/go/lyra"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+func ExampleAction_Resolve() {
+	pcore.Do(func(c px.Context) {
+		for _, a := range []*lyra.Action{
+			{Do: func() statusOut { return statusOut{`ok`} }},
+			{Do: func() statusOut { return statusOut{`ok`} }, Undo: func(in statusOut) { fmt.Println(`undo`, in.Status) }},
+		} {
+			s := resolve(c, a).(wf.Action)
+			f := s.Function().(px.PuppetObject)
+			t := f.PType().(px.ObjectType)
+			_, hasUndo := t.Member(`undo`)
+			fmt.Printf("%s %t '%s'\n", t.Name(), hasUndo, s.Undo())
+			if m, ok := t.Member(`undo`); ok {
+				m.Call(c, f, nil, []px.Value{px.SingletonMap(`status`, px.Wrap(c, `ok`))})
+			}
+		}
+	})
+
+	// Output:
+	// Lyra::Action false ''
+	// Lyra::UndoableAction true 'undo'
+	// undo ok
+}
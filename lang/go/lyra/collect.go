@@ -1,7 +1,9 @@
 package lyra
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/lyraproj/servicesdk/serviceapi"
 
@@ -18,21 +20,59 @@ type Collect struct {
 	When string
 
 	// Times denotes an iteration that will happen given number of times. It is mutually exclusive
-	// to Each
+	// to Each, EachPair, Range, While, and Until
 	//
 	// The value must be either a literal integer or the zero value of a struct with one field of
 	// integer type that becomes an parameters variable of the step
 	Times interface{}
 
-	// Each denotes the values to iterate over. It is mutually exclusive to Times.
+	// Each denotes the values to iterate over. It is mutually exclusive to Times, EachPair, Range,
+	// While, and Until.
 	//
 	// The value must be either a literal slice or the zero value of a struct with one field of
 	// slice type that becomes an parameters variable of the step
 	Each interface{}
 
+	// EachPair denotes the key and value pairs to iterate over. It is mutually exclusive to Times,
+	// Each, Range, While, and Until.
+	//
+	// The value must be either a literal map or the zero value of a struct with one field of
+	// map type that becomes an parameters variable of the step
+	EachPair interface{}
+
+	// Range denotes the inclusive range of integers to iterate over. It is mutually exclusive to
+	// Times, Each, EachPair, While, and Until.
+	//
+	// The value must be either a literal slice or array of two integers or the zero value of a
+	// struct with one field of such type that becomes an parameters variable of the step
+	Range interface{}
+
+	// While is a Condition in string form over the returns of the step. The step is applied once and
+	// then again for as long as the condition is true. The returns of each application are passed as
+	// parameters to the next. It is mutually exclusive to Times, Each, EachPair, Range, and Until
+	While string
+
+	// Until is like While but the step is applied again until the condition is true. It is mutually
+	// exclusive to Times, Each, EachPair, Range, and While
+	Until string
+
+	// MaxIterations is the maximum number of iterations when While or Until is used. Zero means
+	// wf.DefaultMaxIterations
+	MaxIterations int
+
+	// Parallelism is the maximum number of iterations that execute concurrently. Zero means that the
+	// number is unlimited
+	Parallelism int
+
+	// CollectErrors makes all iterations execute even when some of them fail, in which case the errors
+	// of all failed iterations are reported together. The default is to fail fast, i.e. to not start new
+	// iterations once an iteration has failed
+	CollectErrors bool
+
 	// As is the variable or variables that is the parameters of each iteration. The producer
 	// must declare these variables as parameters. It must be either a single string, a slice
-	// of strings, or the zero value of a struct.
+	// of strings, or the zero value of a struct. It is optional when While or Until is used in
+	// which case the first variable is the number of the iteration.
 	As interface{}
 
 	// Return is the name of the slice that represents the collected data (the returns of this
@@ -47,63 +87,110 @@ type Collect struct {
 func (e *Collect) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
 	var v px.Value
 	var style wf.IterationStyle
+	set := make([]string, 0, 1)
 	if e.Times != nil {
-		if e.Each != nil {
-			panic(px.Error(MutuallyExclusiveFields, issue.H{`fields`: []string{`Times`, `Each`}}))
-		}
+		set = append(set, `Times`)
 		v = value(c, e.Times)
 		style = wf.IterationStyleTimes
-	} else if e.Each == nil {
-		panic(px.Error(RequireOneOfFields, issue.H{`fields`: []string{`Times`, `Each`}}))
-	} else {
-		v = value(c, e.Times)
+	}
+	if e.Each != nil {
+		set = append(set, `Each`)
+		v = value(c, e.Each)
 		style = wf.IterationStyleEach
 	}
+	if e.EachPair != nil {
+		set = append(set, `EachPair`)
+		v = value(c, e.EachPair)
+		style = wf.IterationStyleEachPair
+	}
+	if e.Range != nil {
+		set = append(set, `Range`)
+		v = value(c, e.Range)
+		style = wf.IterationStyleRange
+	}
+	if e.While != `` {
+		set = append(set, `While`)
+		v = wf.Parse(e.While)
+		style = wf.IterationStyleWhile
+	}
+	if e.Until != `` {
+		set = append(set, `Until`)
+		v = wf.Parse(e.Until)
+		style = wf.IterationStyleUntil
+	}
+	switch len(set) {
+	case 0:
+		panic(px.Error(RequireOneOfFields, issue.H{`fields`: []string{`Times`, `Each`, `EachPair`, `Range`, `While`, `Until`}}))
+	case 1:
+	default:
+		panic(px.Error(MutuallyExclusiveFields, issue.H{`fields`: set}))
+	}
 
 	if e.Step == nil {
 		panic(px.Error(MissingRequiredField, issue.H{`type`: `Collect`, `name`: `Producer`}))
 	}
 
-	if e.As == nil {
+	var vars []serviceapi.Parameter
+	if e.As != nil {
+		vars = asParams(c, e.As)
+	} else if style != wf.IterationStyleWhile && style != wf.IterationStyleUntil {
 		panic(px.Error(MissingRequiredField, issue.H{`type`: `Collect`, `name`: `As`}))
 	}
 
-	return wf.MakeIterator(
-		n, loc, wf.Parse(e.When), nil, nil, style, e.Step.Resolve(c, n, loc), v, asParams(c, e.As), issue.FirstToLower(e.Return))
+	it := wf.MakeIterator(
+		n, loc, wf.Parse(e.When), nil, nil, style, e.Step.Resolve(c, n, loc), v, vars, issue.FirstToLower(e.Return))
+	wf.WithParallelism(wf.WithMaxIterations(it, e.MaxIterations), e.Parallelism)
+	return wf.WithCollectErrors(it, e.CollectErrors)
 }
 
-// value is like px.Wrap but transforms single element zero element structs into parameters
+// value is like px.Wrap but transforms zero value structs with a single field into parameters. The entries
+// of a map are sorted by key.
 func value(c px.Context, uv interface{}) px.Value {
 	rv := reflect.ValueOf(uv)
 	switch rv.Kind() {
 	case reflect.Ptr:
 		e := rv.Elem()
-		if e.Len() == 1 && !e.Field(0).IsValid() {
+		if e.Kind() == reflect.Struct && e.NumField() == 1 && e.IsZero() {
 			return paramFromStruct(c, e)
 		}
 	case reflect.Struct:
-		if rv.Len() == 1 && !rv.Field(0).IsValid() {
+		if rv.NumField() == 1 && rv.IsZero() {
 			return paramFromStruct(c, rv)
 		}
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		l := rv.Len()
 		es := make([]px.Value, l)
 		for i := 0; i < l; i++ {
-			es[i] = value(c, rv.Index(i))
+			es[i] = value(c, rv.Index(i).Interface())
 		}
 		return types.WrapValues(es)
 	case reflect.Map:
 		ks := rv.MapKeys()
+		sort.Slice(ks, func(i, j int) bool { return lessKey(ks[i], ks[j]) })
 		l := len(ks)
 		es := make([]*types.HashEntry, l)
 		for i, k := range ks {
-			es[i] = types.WrapHashEntry(value(c, k), value(c, rv.MapIndex(k)))
+			es[i] = types.WrapHashEntry(value(c, k.Interface()), value(c, rv.MapIndex(k).Interface()))
 		}
 		return types.WrapHash(es)
 	}
 	return px.Wrap(c, uv)
 }
 
+// lessKey orders map keys so that the entries of a hash created from a go map are in a predictable order.
+// Numeric keys are ordered by value and all other keys by their string form.
+func lessKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	}
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
+
 func paramsFromString(n string) []serviceapi.Parameter {
 	return []serviceapi.Parameter{paramFromString(n)}
 }
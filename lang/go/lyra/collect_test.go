@@ -0,0 +1,28 @@
+package lyra_test
+
+import (
+	"fmt"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/lang/go/lyra"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+func ExampleCollect_Resolve_eachPair() {
+	pcore.Do(func(c px.Context) {
+		s := (&lyra.Collect{
+			EachPair: map[int]string{10: `ten`, 9: `nine`, 100: `hundred`},
+			As:       []string{`key`, `value`},
+			Step: &lyra.Action{Do: func(in struct {
+				Key   int
+				Value string
+			}) {
+			}}}).Resolve(c, `My::Collect`, issue.ParseLocation(`(file: /test/x.go)`))
+		fmt.Println(s.(wf.Iterator).Over())
+	})
+
+	// Output:
+	// {9 => 'nine', 10 => 'ten', 100 => 'hundred'}
+}
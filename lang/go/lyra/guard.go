@@ -0,0 +1,39 @@
+package lyra
+
+import (
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// Guard is a step that executes its Body and, when the body fails, its Handler instead.
+type Guard struct {
+	// When is a Condition in string form. Can be left empty
+	When string
+
+	// Parameters is an optional zero value of a struct or a pointer to a struct. The exported fields
+	// of that struct defines the parameters of the guard step
+	Parameters interface{}
+
+	// Return is an optional zero value of a struct or a pointer to a struct. The exported fields
+	// of that struct defines the returns of the guard step
+	Return interface{}
+
+	// Body is the step that is guarded
+	Body Step
+
+	// Handler is the step that is executed when the Body fails. It receives the serviceapi.ErrorObject
+	// that describes the failure in a parameter named "error"
+	Handler Step
+}
+
+func (g *Guard) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
+	if g.Body == nil {
+		panic(px.Error(MissingRequiredField, issue.H{`type`: `Guard`, `name`: `Body`}))
+	}
+	if g.Handler == nil {
+		panic(px.Error(MissingRequiredField, issue.H{`type`: `Guard`, `name`: `Handler`}))
+	}
+	return wf.MakeGuard(n, loc, wf.Parse(g.When), ParametersFromGoStruct(c, g.Parameters), ParametersFromGoStruct(c, g.Return),
+		g.Body.Resolve(c, n+`::body`, loc), g.Handler.Resolve(c, n+`::handler`, loc))
+}
@@ -0,0 +1,183 @@
+package lyra
+
+import (
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// CrudHandler adapts a go value with typed Create, Read, Update, and Delete methods to a handler that conforms
+// to Lyra::CRUD, or to Lyra::CRD when the go value has no Update method. The adapter converts between the
+// untyped states used by the workflow engine and the go type of the handled state and maps the error
+// wf.NotFound to the NotFound error that the workflow engine recognizes.
+type CrudHandler struct {
+	name      string
+	goType    reflect.Type
+	stateType px.ObjectType
+	create    reflect.Value
+	read      reflect.Value
+	update    reflect.Value
+	delete    reflect.Value
+}
+
+var stringType = reflect.TypeOf(``)
+
+// NewCrudHandler creates a CrudHandler for the given handler and state. The state is the zero value of the handled
+// state, typically a pointer to a struct. The type of the state must be registered with the implementation registry
+// of the context. The handler must have the methods:
+//
+//	Create(state *S) (*S, string, error)
+//	Read(externalId string) (*S, error)
+//	Delete(externalId string) error
+//
+// where S is the type of the state. The error returns are optional. An Update method is optional:
+//
+//	Update(externalId string, state *S) (*S, error)
+//
+// The Read, Update, and Delete methods return wf.NotFound when no state can be found for the given external id.
+func NewCrudHandler(c px.Context, handler, state interface{}) *CrudHandler {
+	st := reflect.TypeOf(state)
+	if st.Kind() == reflect.Struct {
+		st = reflect.PtrTo(st)
+	}
+	t, ok := c.ImplementationRegistry().ReflectedToType(st)
+	if ok {
+		_, ok = t.(px.ObjectType)
+	}
+	if !ok {
+		panic(px.Error(NotRegisteredType, issue.H{`type`: st.String()}))
+	}
+
+	hv := reflect.ValueOf(handler)
+	h := &CrudHandler{name: hv.Type().String(), goType: st, stateType: t.(px.ObjectType)}
+	h.create = h.method(hv, `Create`, true, []reflect.Type{st}, st, stringType)
+	h.read = h.method(hv, `Read`, true, []reflect.Type{stringType}, st)
+	h.update = h.method(hv, `Update`, false, []reflect.Type{stringType, st}, st)
+	h.delete = h.method(hv, `Delete`, true, []reflect.Type{stringType})
+	return h
+}
+
+// method returns the method with the given name after asserting that it takes the given parameters and
+// returns the given values, optionally followed by an error. An invalid value is returned when the method
+// is missing and not required.
+func (h *CrudHandler) method(hv reflect.Value, name string, required bool, in []reflect.Type, out ...reflect.Type) reflect.Value {
+	m := hv.MethodByName(name)
+	if !m.IsValid() {
+		if required {
+			panic(px.Error(MissingHandlerMethod, issue.H{`type`: hv.Type().String(), `method`: name}))
+		}
+		return m
+	}
+
+	mt := m.Type()
+	ok := !mt.IsVariadic() && mt.NumIn() == len(in)
+	for i := 0; ok && i < len(in); i++ {
+		ok = mt.In(i) == in[i]
+	}
+	oc := mt.NumOut()
+	if ok && oc == len(out)+1 {
+		ok = mt.Out(len(out)).AssignableTo(errorInterface)
+		oc--
+	}
+	ok = ok && oc == len(out)
+	for i := 0; ok && i < len(out); i++ {
+		ok = mt.Out(i) == out[i]
+	}
+	if !ok {
+		panic(badFunction(hv.Type().String()+`.`+name, mt))
+	}
+	return m
+}
+
+// StateType returns the type of the state that this handler handles
+func (h *CrudHandler) StateType() px.Type {
+	return h.stateType
+}
+
+// Call converts the arguments of the 'create', 'read', 'update', and 'delete' methods into the go values
+// required by the corresponding typed method, calls the method, and converts its result back into values
+// that conform to Lyra::CRUD. Call will return nil, false for any other method, and for 'update' when the
+// handler has no Update method.
+func (h *CrudHandler) Call(c px.Context, method px.ObjFunc, args []px.Value, block px.Lambda) (px.Value, bool) {
+	switch method.Name() {
+	case `create`:
+		result := h.call(`Create`, h.create, ``, h.reflectState(c, args[0]))
+		if result[0].IsNil() {
+			panic(px.Error(NilState, issue.H{`name`: h.name + `.Create`}))
+		}
+		return types.WrapValues([]px.Value{px.WrapReflected(c, result[0]), types.WrapString(result[1].String())}), true
+	case `read`:
+		extId := args[0].String()
+		return px.WrapReflected(c, h.call(`Read`, h.read, extId, reflect.ValueOf(extId))[0]), true
+	case `update`:
+		if h.update.IsValid() {
+			extId := args[0].String()
+			return px.WrapReflected(c, h.call(`Update`, h.update, extId, reflect.ValueOf(extId), h.reflectState(c, args[1]))[0]), true
+		}
+	case `delete`:
+		extId := args[0].String()
+		h.call(`Delete`, h.delete, extId, reflect.ValueOf(extId))
+		return types.BooleanTrue, true
+	}
+	return nil, false
+}
+
+// call calls the given method with the given arguments and returns its result. A returned error is raised. The
+// error wf.NotFound, or an error that wraps it, is raised as the NotFound error for the given external id.
+func (h *CrudHandler) call(name string, m reflect.Value, extId string, args ...reflect.Value) []reflect.Value {
+	result := m.Call(args)
+	last := len(result) - 1
+	if last >= 0 && m.Type().Out(last).AssignableTo(errorInterface) {
+		if !result[last].IsNil() {
+			err := result[last].Interface().(error)
+			if errors.Is(err, wf.NotFound) {
+				panic(serviceapi.NotFound(h.stateType.Name(), extId))
+			}
+			panic(px.Error(px.GoFunctionError, issue.H{`name`: h.name + `.` + name, `error`: err}))
+		}
+		result = result[:last]
+	}
+	return result
+}
+
+func (h *CrudHandler) reflectState(c px.Context, state px.Value) reflect.Value {
+	sv := reflect.New(h.goType).Elem()
+	c.Reflector().ReflectTo(state, sv)
+	return sv
+}
+
+func (h *CrudHandler) String() string {
+	return px.ToString(h)
+}
+
+func (h *CrudHandler) Equals(value interface{}, guard px.Guard) bool {
+	return h == value
+}
+
+func (h *CrudHandler) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(h, format, bld, g)
+}
+
+func (h *CrudHandler) PType() px.Type {
+	if h.update.IsValid() {
+		return wf.CrudType
+	}
+	return wf.CrdType
+}
+
+func (h *CrudHandler) Get(key string) (value px.Value, ok bool) {
+	if key == `name` {
+		return types.WrapString(h.name), true
+	}
+	return nil, false
+}
+
+func (h *CrudHandler) InitHash() px.OrderedMap {
+	return px.SingletonMap(`name`, types.WrapString(h.name))
+}
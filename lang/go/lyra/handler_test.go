@@ -0,0 +1,139 @@
+package lyra_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/lang/go/lyra"
+	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+type Item struct {
+	Id   *string
+	Name string
+}
+
+// itemHandler has no Update method so it conforms to Lyra::CRD
+type itemHandler struct {
+	items map[string]*Item
+}
+
+func (h *itemHandler) Create(i *Item) (*Item, string, error) {
+	if i.Name == `` {
+		return nil, ``, nil
+	}
+	id := fmt.Sprint(len(h.items) + 1)
+	i.Id = &id
+	h.items[id] = i
+	return i, id, nil
+}
+
+func (h *itemHandler) Read(id string) (*Item, error) {
+	if i, ok := h.items[id]; ok {
+		return i, nil
+	}
+	return nil, fmt.Errorf(`read item %s: %w`, id, wf.NotFound)
+}
+
+func (h *itemHandler) Delete(id string) error {
+	return errors.New(`items cannot be deleted`)
+}
+
+type badCreateHandler struct{}
+
+func (badCreateHandler) Create(i Item) (*Item, string) { return nil, `` }
+func (badCreateHandler) Read(id string) *Item          { return nil }
+func (badCreateHandler) Delete(id string)              {}
+
+type badReadHandler struct{}
+
+func (badReadHandler) Create(i *Item) (*Item, string)  { return nil, `` }
+func (badReadHandler) Read(id string) (*Item, bool)    { return nil, false }
+func (badReadHandler) Delete(id string)                {}
+func (badReadHandler) Update(id string, i *Item) *Item { return nil }
+
+type noDeleteHandler struct{}
+
+func (noDeleteHandler) Create(i *Item) (*Item, string) { return nil, `` }
+func (noDeleteHandler) Read(id string) *Item           { return nil }
+
+func addItemType(c px.Context) {
+	px.AddTypes(c, c.Reflector().TypeFromReflect(`Test::Item`, nil, reflect.TypeOf(&Item{})))
+}
+
+// callHandler calls the given method of the handler in the same way as a service does and prints the result
+// or the code of the raised error
+func callHandler(c px.Context, h *lyra.CrudHandler, method string, args ...px.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println(method, r.(issue.Reported).Code())
+		}
+	}()
+	m, _ := h.PType().(px.TypeWithCallableMembers).Member(method)
+	fmt.Println(method, m.Call(c, h, nil, args))
+}
+
+func ExampleNewCrudHandler() {
+	pcore.Do(func(c px.Context) {
+		addItemType(c)
+		h := lyra.NewCrudHandler(c, &itemHandler{map[string]*Item{}}, &Item{})
+		fmt.Println(h.PType().Name(), h.StateType().Name())
+
+		callHandler(c, h, `create`, px.Wrap(c, &Item{Name: `pen`}))
+		callHandler(c, h, `read`, types.WrapString(`1`))
+		callHandler(c, h, `read`, types.WrapString(`2`))
+		callHandler(c, h, `delete`, types.WrapString(`1`))
+		callHandler(c, h, `create`, px.Wrap(c, &Item{}))
+	})
+
+	// Output:
+	// Lyra::CRD Test::Item
+	// create [Test::Item('name' => 'pen', 'id' => '1'), '1']
+	// read Test::Item('name' => 'pen', 'id' => '1')
+	// read WF_NOT_FOUND
+	// delete PCORE_GO_FUNCTION_ERROR
+	// create WF_NIL_STATE
+}
+
+func ExampleNewCrudHandler_errors() {
+	pcore.Do(func(c px.Context) {
+		addItemType(c)
+		for _, handler := range []interface{}{&badCreateHandler{}, &badReadHandler{}, &noDeleteHandler{}} {
+			func() {
+				defer func() { fmt.Println(recover().(issue.Reported).Code()) }()
+				lyra.NewCrudHandler(c, handler, &Item{})
+			}()
+		}
+
+		func() {
+			defer func() { fmt.Println(recover().(issue.Reported).Code()) }()
+			lyra.NewCrudHandler(c, &itemHandler{}, &service.Server{})
+		}()
+	})
+
+	// Output:
+	// WF_BAD_FUNCTION
+	// WF_BAD_FUNCTION
+	// WF_MISSING_HANDLER_METHOD
+	// WF_NOT_REGISTERED_TYPE
+}
+
+func ExampleStateHandler_Resolve() {
+	pcore.Do(func(c px.Context) {
+		addItemType(c)
+		s := (&lyra.StateHandler{
+			State:   &Item{},
+			Handler: &itemHandler{map[string]*Item{}},
+			Timeout: 5 * time.Second}).Resolve(c, `My::ItemHandler`, issue.ParseLocation(`(file: /test/x.go)`))
+		fmt.Println(s.(wf.StateHandler).HandlerFor().Name(), s.(wf.Timed).Timeout())
+	})
+
+	// Output: Test::Item 5s
+}
@@ -6,22 +6,30 @@ import (
 
 const (
 	BadFunction             = `WF_BAD_FUNCTION`
+	MissingHandlerMethod    = `WF_MISSING_HANDLER_METHOD`
 	MissingRequiredField    = `WF_MISSING_STEP_NAME`
 	MutuallyExclusiveFields = `WF_MUTUALLY_EXCLUSIVE_FIELDS`
+	NilState                = `WF_NIL_STATE`
 	NotActionFunction       = `WF_NOT_STATE_FUNCTION`
 	NotOneStructField       = `WF_NOT_ONE_STRUCT_FIELD`
+	NotRegisteredType       = `WF_NOT_REGISTERED_TYPE`
 	NotStateFunction        = `WF_NOT_STATE_FUNCTION`
 	NotStruct               = `WF_NOT_STRUCT`
 	RequireOneOfFields      = `WF_REQUIRE_ONE_OF_FIELDS`
+	UnknownUndoParameter    = `WF_UNKNOWN_UNDO_PARAMETER`
 )
 
 func init() {
 	issue.Hard(BadFunction, `the go func %{name} has invalid signature: %{type}`)
+	issue.Hard(MissingHandlerMethod, `the handler %{type} has no %{method} method`)
 	issue.Hard(MissingRequiredField, `missing required field %{type}.%{name}`)
 	issue.Hard2(MutuallyExclusiveFields, `only one of the %{fields} can have a value`, issue.HF{`fields`: issue.JoinErrors})
+	issue.Hard(NilState, `%{name} returned a nil state`)
 	issue.Hard(NotOneStructField, `struct describing parameter must have exactly one field, got %{type}`)
 	issue.Hard(NotActionFunction, `expected action %{name} function to be a go func, got %{type}`)
+	issue.Hard(NotRegisteredType, `the state type %{type} has not been registered`)
 	issue.Hard(NotStateFunction, `expected resource %{name} state function to be a go func, got %{type}`)
 	issue.Hard(NotStruct, `%{name} argument must be a go struct or a pointer to a go struct, got '%{type}'`)
 	issue.Hard2(RequireOneOfFields, `one of the %{fields} must have a value`, issue.HF{`fields`: issue.JoinErrors})
+	issue.Hard(UnknownUndoParameter, `the undo function of action %{name} has parameter '%{parameter}' which is neither a parameter nor a return of the action`)
 }
@@ -0,0 +1,115 @@
+package lyra_test
+
+import (
+	"fmt"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/lang/go/lyra"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+type statusOut struct {
+	Status string
+}
+
+func noop() {}
+
+func statusAction() lyra.Step {
+	return &lyra.Action{Do: func() statusOut { return statusOut{`ok`} }}
+}
+
+func resolve(c px.Context, s lyra.Step) wf.Step {
+	return s.Resolve(c, `My::Step`, issue.ParseLocation(`(file: /test/x.go)`))
+}
+
+func ExampleGuard_Resolve() {
+	pcore.Do(func(c px.Context) {
+		g := resolve(c, &lyra.Guard{
+			Body: statusAction(),
+			Handler: &lyra.Action{Do: func(in struct{ Error px.Value }) statusOut {
+				return statusOut{`failed`}
+			}}}).(wf.Guard)
+		fmt.Println(g.Label())
+		fmt.Println(g.Body().Name(), g.Handler().Name())
+		fmt.Println(wf.StepParameters(g), wf.ReturnedNames(g))
+	})
+
+	// Output:
+	// guard My::Step
+	// My::Step::body My::Step::handler
+	// [] [status]
+}
+
+func ExampleSwitch_Resolve() {
+	pcore.Do(func(c px.Context) {
+		s := resolve(c, &lyra.Switch{
+			Cases:   []lyra.Case{{When: `env == 'prod'`, Step: statusAction()}},
+			Default: statusAction()}).(wf.Switch)
+		for _, env := range []string{`prod`, `test`} {
+			fmt.Println(env, wf.Select(s, px.SingletonMap(`env`, px.Wrap(c, env))).Name())
+		}
+	})
+
+	// Output:
+	// prod My::Step::case0
+	// test My::Step::default
+}
+
+func ExampleStep_Resolve_errors() {
+	pcore.Do(func(c px.Context) {
+		for _, s := range []lyra.Step{
+			&lyra.Action{Do: `not a function`},
+			&lyra.Action{Do: func(a, b struct{}) {}},
+			&lyra.Action{Do: func() (statusOut, statusOut) { return statusOut{}, statusOut{} }},
+			&lyra.Action{Do: noop, Undo: func(in struct{ Other string }) {}},
+			&lyra.Collect{Step: statusAction(), As: `x`},
+			&lyra.Collect{Times: 2, Each: []int{1}, Step: statusAction(), As: `x`},
+			&lyra.Collect{Times: 2, As: `x`},
+			&lyra.Collect{Times: 2, Step: statusAction()},
+			&lyra.Guard{Handler: statusAction()},
+			&lyra.Guard{Body: statusAction()},
+			&lyra.Switch{},
+			&lyra.Switch{Cases: []lyra.Case{{When: `prod`}}},
+			&lyra.Switch{Cases: []lyra.Case{{When: `prod`, Step: statusAction()}}, Default: &lyra.Action{Do: noop}},
+			&lyra.Wait{Resource: `db`, API: `My::Api`, Until: `ready`},
+			&lyra.Wait{Until: `ready`},
+			&lyra.Wait{Resource: `db`},
+			&lyra.StateHandler{Handler: noop},
+		} {
+			func() {
+				defer func() {
+					r := recover().(issue.Reported)
+					fmt.Printf("%T %s", s, r.Code())
+					for _, a := range []string{`name`, `fields`, `parameter`} {
+						if v := r.Argument(a); v != nil {
+							fmt.Print(` `, v)
+						}
+					}
+					fmt.Println()
+				}()
+				resolve(c, s)
+			}()
+		}
+	})
+
+	// Output:
+	// *lyra.Action WF_NOT_STATE_FUNCTION My::Step
+	// *lyra.Action WF_BAD_FUNCTION My::Step
+	// *lyra.Action WF_BAD_FUNCTION My::Step
+	// *lyra.Action WF_UNKNOWN_UNDO_PARAMETER My::Step other
+	// *lyra.Collect WF_REQUIRE_ONE_OF_FIELDS [Times Each EachPair Range While Until]
+	// *lyra.Collect WF_MUTUALLY_EXCLUSIVE_FIELDS [Times Each]
+	// *lyra.Collect WF_MISSING_STEP_NAME Producer
+	// *lyra.Collect WF_MISSING_STEP_NAME As
+	// *lyra.Guard WF_MISSING_STEP_NAME Body
+	// *lyra.Guard WF_MISSING_STEP_NAME Handler
+	// *lyra.Switch WF_MISSING_STEP_NAME Cases
+	// *lyra.Switch WF_MISSING_STEP_NAME Step
+	// *lyra.Switch WF_SWITCH_RETURNS_MISMATCH
+	// *lyra.Wait WF_MUTUALLY_EXCLUSIVE_FIELDS [Resource API]
+	// *lyra.Wait WF_REQUIRE_ONE_OF_FIELDS [Resource API]
+	// *lyra.Wait WF_MISSING_STEP_NAME Until
+	// *lyra.StateHandler WF_MISSING_STEP_NAME State
+}
@@ -3,6 +3,7 @@ package lyra
 import (
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/lyraproj/servicesdk/serviceapi"
 
@@ -33,6 +34,12 @@ type Resource struct {
 	// The function can return one or two values. The first value must be a pointer to a struct. That struct represents
 	// the resource type. If an optional second value is returned, it must be of type error.
 	State interface{}
+
+	// Retry is an optional policy that controls how the resource is retried when it cannot be applied
+	Retry *wf.RetryPolicy
+
+	// Timeout is the maximum duration of one resolution of the State function. Zero means no limit
+	Timeout time.Duration
 }
 
 func (r *Resource) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
@@ -124,6 +131,7 @@ func (r *Resource) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
 
 	gs := newGoState(ot, fv, returnsError)
 	rs := wf.MakeResource(n, loc, wf.Parse(r.When), parameters, returns, r.ExternalId, gs)
+	wf.WithTimeout(wf.WithRetry(rs, r.Retry), r.Timeout)
 	gs.resource = rs
 	return rs
 }
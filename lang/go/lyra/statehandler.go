@@ -0,0 +1,41 @@
+package lyra
+
+import (
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// StateHandler declares the handler that creates, reads, updates, and deletes the state of resources of a
+// given type. A service that declares resources can thereby also provide the handler for their states.
+type StateHandler struct {
+	// When is a Condition in string form. Can be left empty
+	When string
+
+	// State is the zero value of the handled state, typically a pointer to a struct. The type of the state
+	// must be registered with the implementation registry of the context.
+	State interface{}
+
+	// Handler is the go value that handles the state. It is adapted to a Lyra::CRUD handler using a
+	// CrudHandler and must therefore have the methods described by NewCrudHandler.
+	Handler interface{}
+
+	// Timeout is the maximum duration of one invocation of a method of the Handler. Zero means no limit
+	Timeout time.Duration
+}
+
+func (h *StateHandler) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
+	if h.State == nil {
+		panic(px.Error(MissingRequiredField, issue.H{`type`: `StateHandler`, `name`: `State`}))
+	}
+	if h.Handler == nil {
+		panic(px.Error(MissingRequiredField, issue.H{`type`: `StateHandler`, `name`: `Handler`}))
+	}
+
+	ch := NewCrudHandler(c, h.Handler, h.State)
+	sh := wf.MakeStateHandler(n, loc, wf.Parse(h.When), nil, nil, ch)
+	wf.WithTimeout(sh, h.Timeout)
+	return wf.WithHandlerFor(sh, ch.StateType())
+}
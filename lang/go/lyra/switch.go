@@ -0,0 +1,58 @@
+package lyra
+
+import (
+	"strconv"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// Switch is a step that executes the Step of its first Case with a condition that is true, or its Default
+// when no such case exists. All cases and the default must have the same returns.
+type Switch struct {
+	// When is a Condition in string form. Can be left empty
+	When string
+
+	// Parameters is an optional zero value of a struct or a pointer to a struct. The exported fields
+	// of that struct defines the parameters of the switch step
+	Parameters interface{}
+
+	// Return is an optional zero value of a struct or a pointer to a struct. The exported fields
+	// of that struct defines the returns of the switch step. Defaults to the returns of the first case
+	Return interface{}
+
+	// Cases are the cases of the switch in the order that they are evaluated
+	Cases []Case
+
+	// Default is the step that is executed when no case is selected. Can be left nil
+	Default Step
+}
+
+// Case is a case of a Switch
+type Case struct {
+	// When is the Condition in string form that selects the case
+	When string
+
+	// Step is the step that is executed when the case is selected
+	Step Step
+}
+
+func (s *Switch) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
+	if len(s.Cases) == 0 {
+		panic(px.Error(MissingRequiredField, issue.H{`type`: `Switch`, `name`: `Cases`}))
+	}
+	cases := make([]wf.Case, len(s.Cases))
+	for i, cs := range s.Cases {
+		if cs.Step == nil {
+			panic(px.Error(MissingRequiredField, issue.H{`type`: `Case`, `name`: `Step`}))
+		}
+		cases[i] = wf.Case{Condition: wf.Parse(cs.When), Step: cs.Step.Resolve(c, n+`::case`+strconv.Itoa(i), loc)}
+	}
+	var dflt wf.Step
+	if s.Default != nil {
+		dflt = s.Default.Resolve(c, n+`::default`, loc)
+	}
+	return wf.MakeSwitch(n, loc, wf.Parse(s.When), ParametersFromGoStruct(c, s.Parameters), ParametersFromGoStruct(c, s.Return),
+		cases, dflt)
+}
@@ -1,5 +1,8 @@
 // Package lyra provides struct types that implement the Step interface. The structs can be used to declare
-// a complete Lyra workflow in Golang
+// a complete Lyra workflow in Golang.
+//
+// Only the steps that invoke something, i.e. Action, Resource, StateHandler, and Wait, have a Timeout. The
+// steps that contain other steps are limited by the timeouts of the steps that they contain.
 package lyra
 
 import (
@@ -0,0 +1,65 @@
+package lyra
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// Wait is a step that repeatedly reads the state of a resource, or invokes a method of an API, until the
+// Until condition holds for the result. The returns of the step are produced from the last result.
+type Wait struct {
+	// When is a Condition in string form. Can be left empty
+	When string
+
+	// Parameters is an optional zero value of a struct or a pointer to a struct. The exported fields
+	// of that struct defines the parameters of the wait step. The parameters are passed to the API method
+	Parameters interface{}
+
+	// Return is an optional zero value of a struct or a pointer to a struct. The exported fields
+	// of that struct defines the returns of the wait step
+	Return interface{}
+
+	// Resource is the name of a resource step in the same workflow as the wait. It is mutually exclusive
+	// to API
+	Resource string
+
+	// API is the identifier of the API that is invoked. It is mutually exclusive to Resource
+	API string
+
+	// Method is the name of the API method that is invoked. Defaults to "read"
+	Method string
+
+	// Until is a Condition in string form that must hold for the state or value that is read
+	Until string
+
+	// Interval is the delay between two attempts
+	Interval time.Duration
+
+	// Timeout is the maximum duration of the wait. Zero means no limit
+	Timeout time.Duration
+}
+
+func (w *Wait) Resolve(c px.Context, n string, loc issue.Location) wf.Step {
+	resource := w.Resource
+	method := w.Method
+	if resource != `` {
+		if w.API != `` {
+			panic(px.Error(MutuallyExclusiveFields, issue.H{`fields`: []string{`Resource`, `API`}}))
+		}
+		// The resource is a sibling of the wait
+		resource = strings.TrimSuffix(n, wf.LeafName(n)) + resource
+	} else if w.API == `` {
+		panic(px.Error(RequireOneOfFields, issue.H{`fields`: []string{`Resource`, `API`}}))
+	} else if method == `` {
+		method = `read`
+	}
+	if w.Until == `` {
+		panic(px.Error(MissingRequiredField, issue.H{`type`: `Wait`, `name`: `Until`}))
+	}
+	return wf.WithTimeout(wf.MakeWait(n, loc, wf.Parse(w.When), ParametersFromGoStruct(c, w.Parameters),
+		ParametersFromGoStruct(c, w.Return), resource, w.API, method, wf.Parse(w.Until), w.Interval), w.Timeout)
+}
@@ -0,0 +1,23 @@
+package manifest
+
+import "github.com/lyraproj/issue/issue"
+
+const (
+	FieldTypeMismatch = `WF_MANIFEST_FIELD_TYPE_MISMATCH`
+	MissingField      = `WF_MANIFEST_MISSING_FIELD`
+	MutuallyExclusive = `WF_MANIFEST_MUTUALLY_EXCLUSIVE`
+	ReadError         = `WF_MANIFEST_READ_ERROR`
+	StepStyle         = `WF_MANIFEST_STEP_STYLE`
+	UnknownField      = `WF_MANIFEST_UNKNOWN_FIELD`
+	UnknownOver       = `WF_MANIFEST_UNKNOWN_OVER`
+)
+
+func init() {
+	issue.Hard(FieldTypeMismatch, `expected '%{field}' to be %{expected}, got %{actual}`)
+	issue.Hard(MissingField, `%{context} is missing required field '%{field}'`)
+	issue.Hard(MutuallyExclusive, `the fields %{fields} are mutually exclusive`)
+	issue.Hard(ReadError, `unable to read manifest: %{detail}`)
+	issue.Hard(StepStyle, `step '%{step}' must have exactly one of 'steps', 'call', or 'iterator'`)
+	issue.Hard(UnknownField, `unknown field '%{field}' in %{context}`)
+	issue.Hard(UnknownOver, `iterator '%{step}' iterates over '%{name}' which is not one of its parameters`)
+}
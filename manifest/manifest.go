@@ -0,0 +1,369 @@
+// Package manifest declares workflows from YAML or JSON manifests. A manifest composes steps that are
+// implemented elsewhere, e.g. by handler plugins, into workflows without the need to write Go.
+//
+// A manifest describes one step. A step is a workflow when it has "steps", a call when it has "call", and
+// an iterator when it has "iterator". All steps can have "parameters", "returns", and a "when" condition:
+//
+//	name: My::Deploy
+//	parameters:
+//	  region: String
+//	  count:
+//	    type: Integer
+//	    value: 2
+//	  owner:
+//	    type: String
+//	    lookup: aws.owner
+//	returns:
+//	  subnetIds: Array[String]
+//	steps:
+//	  vpc:
+//	    call: Aws::Vpc
+//	    parameters:
+//	      region: String
+//	    returns:
+//	      vpcId: String
+//	  subnets:
+//	    when: vpcId
+//	    parameters:
+//	      count: Integer
+//	      vpcId: String
+//	    iterator:
+//	      style: times
+//	      over: count
+//	      variables:
+//	        index: Integer
+//	      into: subnetIds
+//	      step:
+//	        call: Aws::Subnet
+//	        parameters:
+//	          index: Integer
+//	          vpcId: String
+//	        returns:
+//	          subnetId: String
+//
+// A parameter is declared using either a type string, or a hash with the required "type" and the optional
+// "alias", "value", and "lookup" fields. The "value" and "lookup" fields are mutually exclusive. Returns use the
+// same syntax but cannot have a value or lookup.
+//
+// The "over" field of an iterator is either the name of a parameter of the iterator or a literal value. For the
+// "while" and "until" styles, it is a condition over the returns of the step and the optional "maxIterations"
+// field limits the number of iterations. The optional "parallelism" field limits the number of concurrent
+// iterations and "collectErrors: true" makes all iterations execute even when some of them fail.
+package manifest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/pcore/yaml"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// Load reads the manifest file at the given path and returns the step that it declares
+func Load(c px.Context, path string) wf.Step {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(px.Error(ReadError, issue.H{`detail`: err.Error()}))
+	}
+	return Parse(c, path, content)
+}
+
+// Parse parses the given YAML or JSON manifest and returns the step that it declares. The given file is
+// used in the locations of reported issues.
+func Parse(c px.Context, file string, content []byte) wf.Step {
+	l := &loader{file: file}
+	root := l.unmarshal(c, content)
+	m := l.hash(root, `manifest`)
+	name := l.requiredString(m, root, `name`, `manifest`)
+	allowed := append([]string{`name`}, stepFields...)
+
+	var step wf.Step
+	switch l.style(m, root, name, allowed) {
+	case `steps`:
+		step = wf.NewWorkflow(c, func(b wf.WorkflowBuilder) { l.workflow(b, name, m, root) })
+	case `call`:
+		step = wf.NewCall(c, func(b wf.CallBuilder) { l.call(b, name, m, root) })
+	default:
+		step = wf.NewIterator(c, func(b wf.IteratorBuilder) { l.iterator(b, name, m, root) })
+	}
+	return step
+}
+
+// The fields that are valid in all steps, including the fields that determine the step style
+var stepFields = []string{`call`, `iterator`, `parameters`, `returns`, `steps`, `when`}
+
+var iteratorFields = []string{`collectErrors`, `into`, `maxIterations`, `over`, `parallelism`, `step`, `style`, `variables`}
+
+var parameterFields = []string{`alias`, `lookup`, `type`, `value`}
+
+type loader struct {
+	file string
+}
+
+func (l *loader) location(v *yaml.Value) issue.Location {
+	if v == nil {
+		return issue.NewLocation(l.file, 0, 0)
+	}
+	return issue.NewLocation(l.file, v.Line, v.Column)
+}
+
+func (l *loader) fail(v *yaml.Value, code issue.Code, args issue.H) {
+	panic(issue.NewReported(code, issue.SeverityError, args, l.location(v)))
+}
+
+var syntaxErrorLine = regexp.MustCompile(`\Ayaml: line (\d+):`)
+
+// unmarshal parses the given content and ensures that a syntax error is reported at the line where it was found
+func (l *loader) unmarshal(c px.Context, content []byte) *yaml.Value {
+	defer func() {
+		if r := recover(); r != nil {
+			if ri, ok := r.(issue.Reported); ok && ri.Code() == px.ParseError {
+				line := 0
+				if m := syntaxErrorLine.FindStringSubmatch(fmt.Sprint(ri.Argument(`detail`))); m != nil {
+					line, _ = strconv.Atoi(m[1])
+				}
+				r = ri.WithLocation(issue.NewLocation(l.file, line, 0))
+			}
+			panic(r)
+		}
+	}()
+	return yaml.UnmarshalWithPositions(c, content)
+}
+
+// at calls the given function and ensures that an issue that it raises is reported at the location of
+// the given value
+func (l *loader) at(v *yaml.Value, f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if ri, ok := r.(issue.Reported); ok {
+				r = ri.WithLocation(l.location(v))
+			}
+			panic(r)
+		}
+	}()
+	f()
+}
+
+// style asserts that the given step hash contains only allowed fields and returns the name of the
+// field that determines its style
+func (l *loader) style(m px.OrderedMap, v *yaml.Value, name string, allowed []string) string {
+	l.assertFields(m, allowed, `step '`+name+`'`)
+	var style string
+	for _, s := range []string{`steps`, `call`, `iterator`} {
+		if _, ok := m.Get4(s); ok {
+			if style != `` {
+				l.fail(v, StepStyle, issue.H{`step`: name})
+			}
+			style = s
+		}
+	}
+	if style == `` {
+		l.fail(v, StepStyle, issue.H{`step`: name})
+	}
+	return style
+}
+
+func (l *loader) assertFields(m px.OrderedMap, allowed []string, context string) {
+	m.EachKey(func(k px.Value) {
+		f := k.String()
+		for _, a := range allowed {
+			if a == f {
+				return
+			}
+		}
+		l.fail(k.(*yaml.Value), UnknownField, issue.H{`field`: f, `context`: context})
+	})
+}
+
+// common applies the name, origin, when condition, parameters, and returns of the given step hash to
+// the given builder
+func (l *loader) common(b wf.Builder, name string, m px.OrderedMap, v *yaml.Value) {
+	b.Name(name)
+	b.Origin(l.location(v))
+	if w, ok := m.Get4(`when`); ok {
+		wv := w.(*yaml.Value)
+		when := l.string(wv, `when`)
+		l.at(wv, func() { b.When(when) })
+	}
+	if ps, ok := m.Get4(`parameters`); ok {
+		b.Parameters(l.parameters(b.Context(), ps.(*yaml.Value), `parameters`, true)...)
+	}
+	if rs, ok := m.Get4(`returns`); ok {
+		b.Returns(l.parameters(b.Context(), rs.(*yaml.Value), `returns`, false)...)
+	}
+}
+
+func (l *loader) workflow(b wf.WorkflowBuilder, name string, m px.OrderedMap, v *yaml.Value) {
+	l.common(b, name, m, v)
+	sv := m.Get5(`steps`, nil).(*yaml.Value)
+	l.hash(sv, `steps`).EachPair(func(k, cv px.Value) {
+		l.child(b, k.String(), cv.(*yaml.Value))
+	})
+}
+
+func (l *loader) call(b wf.CallBuilder, name string, m px.OrderedMap, v *yaml.Value) {
+	l.common(b, name, m, v)
+	b.CallTo(l.string(m.Get5(`call`, nil).(*yaml.Value), `call`))
+}
+
+func (l *loader) iterator(b wf.IteratorBuilder, name string, m px.OrderedMap, v *yaml.Value) {
+	l.common(b, name, m, v)
+	iv := m.Get5(`iterator`, nil).(*yaml.Value)
+	im := l.hash(iv, `iterator`)
+	context := `iterator '` + name + `'`
+	l.assertFields(im, iteratorFields, context)
+
+	sv := l.requiredValue(im, iv, `style`, context)
+	style := l.string(sv, `style`)
+	l.at(sv, func() { b.Style(wf.NewIterationStyle(style)) })
+
+	ov := l.requiredValue(im, iv, `over`, context)
+	if style == `while` || style == `until` {
+		cond := l.string(ov, `over`)
+		l.at(ov, func() { b.Over(wf.Parse(cond)) })
+		if mv, ok := im.Get4(`maxIterations`); ok {
+			b.MaxIterations(l.integer(mv.(*yaml.Value), `maxIterations`))
+		}
+	} else if s, ok := ov.Value.(px.StringValue); ok {
+		var over serviceapi.Parameter
+		for _, p := range b.GetParameters() {
+			if p.Name() == s.String() {
+				over = p
+				break
+			}
+		}
+		if over == nil {
+			l.fail(ov, UnknownOver, issue.H{`step`: name, `name`: s.String()})
+		}
+		b.Over(over)
+	} else {
+		b.Over(ov.Unwrap())
+	}
+
+	if vs, ok := im.Get4(`variables`); ok {
+		b.Variables(l.parameters(b.Context(), vs.(*yaml.Value), `variables`, false)...)
+	}
+	if into, ok := im.Get4(`into`); ok {
+		b.Into(l.string(into.(*yaml.Value), `into`))
+	}
+	if pv, ok := im.Get4(`parallelism`); ok {
+		b.Parallelism(l.integer(pv.(*yaml.Value), `parallelism`))
+	}
+	if cv, ok := im.Get4(`collectErrors`); ok {
+		b.CollectErrors(l.boolean(cv.(*yaml.Value), `collectErrors`))
+	}
+
+	// The producer has the same leaf name as the iterator
+	l.child(b, name, l.requiredValue(im, iv, `step`, context))
+}
+
+func (l *loader) child(b wf.ChildBuilder, name string, v *yaml.Value) {
+	m := l.hash(v, `step '`+name+`'`)
+	switch l.style(m, v, name, stepFields) {
+	case `steps`:
+		b.Workflow(func(cb wf.WorkflowBuilder) { l.workflow(cb, name, m, v) })
+	case `call`:
+		b.Call(func(cb wf.CallBuilder) { l.call(cb, name, m, v) })
+	default:
+		b.Iterator(func(cb wf.IteratorBuilder) { l.iterator(cb, name, m, v) })
+	}
+}
+
+// parameters creates parameters from the given hash of parameter declarations. Values and lookups are
+// only permitted when withValue is true.
+func (l *loader) parameters(c px.Context, v *yaml.Value, field string, withValue bool) []serviceapi.Parameter {
+	m := l.hash(v, field)
+	ps := make([]serviceapi.Parameter, 0, m.Len())
+	allowed := parameterFields
+	if !withValue {
+		allowed = []string{`alias`, `type`}
+	}
+	m.EachPair(func(k, pv px.Value) {
+		name := k.String()
+		yv := pv.(*yaml.Value)
+		var typeValue *yaml.Value
+		var alias string
+		var value px.Value
+		if _, ok := yv.Value.(px.StringValue); ok {
+			typeValue = yv
+		} else {
+			pm := l.hash(yv, field+`.`+name)
+			context := `parameter '` + name + `'`
+			l.assertFields(pm, allowed, context)
+			typeValue = l.requiredValue(pm, yv, `type`, context)
+			if a, ok := pm.Get4(`alias`); ok {
+				alias = l.string(a.(*yaml.Value), `alias`)
+			}
+			dv, hasValue := pm.Get4(`value`)
+			lv, hasLookup := pm.Get4(`lookup`)
+			if hasValue && hasLookup {
+				l.fail(yv, MutuallyExclusive, issue.H{`fields`: []string{`value`, `lookup`}})
+			}
+			if hasValue {
+				value = dv.(*yaml.Value).Unwrap()
+			} else if hasLookup {
+				value = types.NewDeferred(`lookup`, lv.(*yaml.Value).Unwrap())
+			}
+		}
+		tn := l.string(typeValue, `type`)
+		var t px.Type
+		l.at(typeValue, func() { t = c.ParseType(tn) })
+		ps = append(ps, serviceapi.NewParameter(name, alias, t, value))
+	})
+	return ps
+}
+
+func (l *loader) hash(v *yaml.Value, field string) px.OrderedMap {
+	if m, ok := v.Value.(px.OrderedMap); ok {
+		return m
+	}
+	l.fail(v, FieldTypeMismatch, issue.H{`field`: field, `expected`: `a hash`, `actual`: actual(v)})
+	return nil
+}
+
+func (l *loader) string(v *yaml.Value, field string) string {
+	if s, ok := v.Value.(px.StringValue); ok {
+		return s.String()
+	}
+	l.fail(v, FieldTypeMismatch, issue.H{`field`: field, `expected`: `a string`, `actual`: actual(v)})
+	return ``
+}
+
+func (l *loader) integer(v *yaml.Value, field string) int {
+	if i, ok := v.Value.(px.Integer); ok {
+		return int(i.Int())
+	}
+	l.fail(v, FieldTypeMismatch, issue.H{`field`: field, `expected`: `an integer`, `actual`: actual(v)})
+	return 0
+}
+
+func (l *loader) boolean(v *yaml.Value, field string) bool {
+	if b, ok := v.Value.(px.Boolean); ok {
+		return b.Bool()
+	}
+	l.fail(v, FieldTypeMismatch, issue.H{`field`: field, `expected`: `a boolean`, `actual`: actual(v)})
+	return false
+}
+
+func (l *loader) requiredValue(m px.OrderedMap, v *yaml.Value, field, context string) *yaml.Value {
+	if fv, ok := m.Get4(field); ok {
+		return fv.(*yaml.Value)
+	}
+	l.fail(v, MissingField, issue.H{`field`: field, `context`: context})
+	return nil
+}
+
+func (l *loader) requiredString(m px.OrderedMap, v *yaml.Value, field, context string) string {
+	return l.string(l.requiredValue(m, v, field, context), field)
+}
+
+func actual(v *yaml.Value) string {
+	return strings.ToLower(v.PType().Name())
+}
@@ -0,0 +1,145 @@
+package manifest_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/manifest"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+
+	// Initialize the Parameter constructor
+	_ "github.com/lyraproj/servicesdk/service"
+)
+
+const deploy = `
+name: My::Deploy
+parameters:
+  region: String
+  count:
+    type: Integer
+    value: 2
+  owner:
+    type: String
+    lookup: aws.owner
+returns:
+  subnetIds: Array[String]
+steps:
+  vpc:
+    call: Aws::Vpc
+    parameters:
+      region: String
+    returns:
+      vpcId: String
+  subnets:
+    when: vpcId
+    parameters:
+      count: Integer
+      vpcId: String
+    iterator:
+      style: times
+      over: count
+      variables:
+        index: Integer
+      into: subnetIds
+      step:
+        call: Aws::Subnet
+        parameters:
+          index: Integer
+          vpcId: String
+        returns:
+          subnetId: String
+`
+
+func printStep(indent string, s wf.Step) {
+	fmt.Printf("%s%s %s\n", indent, s.Label(), issue.LocationString(s.Origin()))
+	if s.When() != wf.Always {
+		fmt.Printf("%s  when %s\n", indent, s.When())
+	}
+	for _, p := range s.Parameters() {
+		fmt.Printf("%s  parameter %s\n", indent, p)
+	}
+	for _, r := range s.Returns() {
+		fmt.Printf("%s  returns %s\n", indent, r)
+	}
+	switch s := s.(type) {
+	case wf.Workflow:
+		for _, c := range s.Steps() {
+			printStep(indent+`  `, c)
+		}
+	case wf.Iterator:
+		fmt.Printf("%s  %s over %s into %s\n", indent, s.IterationStyle(), s.Over().(serviceapi.Parameter).Name(), s.Into())
+		printStep(indent+`  `, s.Producer())
+	case wf.Call:
+		fmt.Printf("%s  calls %s\n", indent, s.Call())
+	}
+}
+
+func ExampleParse() {
+	pcore.Do(func(c px.Context) {
+		printStep(``, manifest.Parse(c, `/manifests/deploy.yaml`, []byte(deploy)))
+	})
+
+	// Output:
+	// workflow My::Deploy (file: /manifests/deploy.yaml, line: 2, column: 1)
+	//   parameter Lyra::Parameter('name' => 'region', 'type' => String)
+	//   parameter Lyra::Parameter('name' => 'count', 'type' => Integer, 'value' => 2)
+	//   parameter Lyra::Parameter('name' => 'owner', 'type' => String, 'value' => Deferred('name' => 'lookup', 'arguments' => ['aws.owner']))
+	//   returns Lyra::Parameter('name' => 'subnetIds', 'type' => Array[String])
+	//   call My::Deploy::vpc (file: /manifests/deploy.yaml, line: 15, column: 5)
+	//     parameter Lyra::Parameter('name' => 'region', 'type' => String)
+	//     returns Lyra::Parameter('name' => 'vpcId', 'type' => String)
+	//     calls Aws::Vpc
+	//   iterator My::Deploy::subnets (file: /manifests/deploy.yaml, line: 21, column: 5)
+	//     when vpcId
+	//     parameter Lyra::Parameter('name' => 'count', 'type' => Integer)
+	//     parameter Lyra::Parameter('name' => 'vpcId', 'type' => String)
+	//     times over count into subnetIds
+	//     call My::Deploy::subnets (file: /manifests/deploy.yaml, line: 32, column: 9)
+	//       parameter Lyra::Parameter('name' => 'index', 'type' => Integer)
+	//       parameter Lyra::Parameter('name' => 'vpcId', 'type' => String)
+	//       returns Lyra::Parameter('name' => 'subnetId', 'type' => String)
+	//       calls Aws::Subnet
+}
+
+func ExampleParse_json() {
+	pcore.Do(func(c px.Context) {
+		printStep(``, manifest.Parse(c, `/manifests/vpc.json`, []byte(`{
+  "name": "My::Vpc",
+  "parameters": {"region": {"type": "String", "alias": "location"}},
+  "call": "Aws::Vpc"
+}`)))
+	})
+
+	// Output:
+	// call My::Vpc (file: /manifests/vpc.json, line: 1, column: 1)
+	//   parameter Lyra::Parameter('name' => 'region', 'type' => String, 'alias' => 'location')
+	//   calls Aws::Vpc
+}
+
+func ExampleParse_error() {
+	pcore.Do(func(c px.Context) {
+		for _, m := range []string{
+			strings.Replace(deploy, `over: count`, `over: counter`, 1),
+			strings.Replace(deploy, `when: vpcId`, `when: vpcId and`, 1),
+			strings.Replace(deploy, `region: String`, `region: Strin[`, 1),
+			strings.Replace(deploy, `call: Aws::Vpc`, `cal: Aws::Vpc`, 1),
+			strings.Replace(deploy, `vpcId: String`, `vpcId: [String`, 1),
+		} {
+			func() {
+				defer func() { fmt.Println(recover()) }()
+				manifest.Parse(c, `/manifests/deploy.yaml`, []byte(m))
+			}()
+		}
+	})
+
+	// Output:
+	// iterator 'subnets' iterates over 'counter' which is not one of its parameters (file: /manifests/deploy.yaml, line: 27, column: 13)
+	// unexpected end of condition 'vpcId and' at position 9 (file: /manifests/deploy.yaml, line: 21, column: 11)
+	// expected ']' or a literal, got 'EOF' (file: /manifests/deploy.yaml, line: 4, column: 11)
+	// unknown field 'cal' in step 'vpc' (file: /manifests/deploy.yaml, line: 15, column: 5)
+	// Unable to parse YAML. Detail: yaml: line 19: did not find expected ',' or ']' (file: /manifests/deploy.yaml, line: 19)
+}
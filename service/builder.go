@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/lyraproj/issue/issue"
 	"github.com/lyraproj/pcore/px"
@@ -22,7 +23,10 @@ type Builder struct {
 	callables       map[string]reflect.Value
 	actionApis      map[string]bool
 	states          map[string]wf.State
+	apiTimeouts     map[string]time.Duration
+	stateTimeouts   map[string]time.Duration
 	callableObjects map[string]px.PuppetObject
+	interceptors    []Interceptor
 }
 
 func NewServiceBuilder(ctx px.Context, serviceName string) *Builder {
@@ -35,6 +39,8 @@ func NewServiceBuilder(ctx px.Context, serviceName string) *Builder {
 		steps:           make(map[string]serviceapi.Definition),
 		types:           make(map[string]px.Type),
 		actionApis:      make(map[string]bool),
+		apiTimeouts:     make(map[string]time.Duration),
+		stateTimeouts:   make(map[string]time.Duration),
 		states:          make(map[string]wf.State)}
 }
 
@@ -49,6 +55,12 @@ func (ds *Builder) RegisterStateConverter(sf wf.StateConverter) {
 	ds.stateConverter = sf
 }
 
+// UseInterceptor adds an interceptor that wraps every call to Invoke and State on the built Server. Interceptors
+// are applied in the order they are added, i.e. the first interceptor added is the outermost one.
+func (ds *Builder) UseInterceptor(interceptor Interceptor) {
+	ds.interceptors = append(ds.interceptors, interceptor)
+}
+
 // RegisterAPI registers a struct as an invokable. The callable instance given as the argument becomes the
 // actual receiver the calls.
 func (ds *Builder) RegisterAPI(name string, callable interface{}) {
@@ -179,15 +191,89 @@ func (ds *Builder) registerReflectedType(namespace string, tg px.AnnotatedType)
 	return et
 }
 
-// RegisterStep registers an step
+// RegisterStep registers an step. The when conditions of the step and its nested steps are validated so that
+// all names that they use are declared as parameters of the step or of its enclosing step.
 func (ds *Builder) RegisterStep(step wf.Step) {
 	name := step.Name()
 	if _, found := ds.steps[name]; found {
 		panic(px.Error(AlreadyRegistered, issue.H{`namespace`: px.NsDefinition, `identifier`: name}))
 	}
+	validateWhen(step, nil)
 	ds.steps[name] = ds.createStepDefinition(step)
 }
 
+// validateWhen asserts that all names used in the when condition of the given step and its nested steps are
+// found among the parameters of the step or the given parameters of its enclosing step. The names used in the
+// condition of a while or until iterator must be found among the returns of its producer.
+func validateWhen(step wf.Step, enclosing []serviceapi.Parameter) {
+	params := step.Parameters()
+	validateCondition(step, step.When(), params, enclosing)
+
+	// Only a workflow declares a new scope. The steps contained in other steps can also use the parameters
+	// of the enclosing scope
+	scope := make([]serviceapi.Parameter, 0, len(params)+len(enclosing))
+	scope = append(append(scope, params...), enclosing...)
+
+	switch step := step.(type) {
+	case wf.Workflow:
+		for _, s := range step.Steps() {
+			validateWhen(s, params)
+		}
+	case wf.Iterator:
+		// The iteration variables are available to the producer
+		validateWhen(step.Producer(), append(scope, step.Variables()...))
+		if cond, ok := step.Over().(wf.Condition); ok {
+			validateLoopCondition(step, cond)
+		}
+	case wf.Guard:
+		validateWhen(step.Body(), scope)
+		validateWhen(step.Handler(), scope)
+	case wf.Switch:
+		for _, cs := range step.Cases() {
+			validateCondition(step, cs.Condition, params, enclosing)
+			validateWhen(cs.Step, scope)
+		}
+		if ds := step.Default(); ds != nil {
+			validateWhen(ds, scope)
+		}
+	}
+}
+
+// validateCondition asserts that all names used in the given condition of the given step are found among the
+// given parameters
+func validateCondition(step wf.Step, cond wf.Condition, params, enclosing []serviceapi.Parameter) {
+	if names := cond.Names(); len(names) > 0 {
+		declared := make(map[string]bool, len(params)+len(enclosing))
+		for _, p := range params {
+			declared[p.Name()] = true
+		}
+		for _, p := range enclosing {
+			declared[p.Name()] = true
+		}
+		for _, n := range names {
+			if !declared[n] {
+				panic(issue.NewReported(UndefinedWhenName, issue.SeverityError,
+					issue.H{`step`: step, `when`: cond.String(), `name`: n}, step.Origin()))
+			}
+		}
+	}
+}
+
+// validateLoopCondition asserts that all names used in the given condition of the given while or until iterator
+// are returned by its producer
+func validateLoopCondition(step wf.Iterator, cond wf.Condition) {
+	returned := make(map[string]bool)
+	for _, n := range wf.ReturnedNames(step.Producer()) {
+		returned[n] = true
+	}
+	for _, n := range cond.Names() {
+		if !returned[n] {
+			panic(issue.NewReported(UndefinedLoopName, issue.SeverityError,
+				issue.H{`step`: step, `condition`: cond.String(), `name`: n, `producer`: step.Producer()}, step.Origin()))
+		}
+	}
+}
+
 func (ds *Builder) registerCallable(name string, callable reflect.Value) {
 	if _, found := ds.callables[name]; found {
 		panic(px.Error(AlreadyRegistered, issue.H{`namespace`: px.NsInterface, `identifier`: name}))
@@ -216,7 +302,17 @@ func (ds *Builder) createStepDefinition(step wf.Step) serviceapi.Definition {
 		props = append(props, types.WrapHashEntry2(`returns`, returns))
 	}
 	if step.When() != wf.Always {
-		props = append(props, types.WrapHashEntry2(`when`, types.WrapString(step.When().String())))
+		props = append(props, types.WrapHashEntry2(`when`, step.When()))
+	}
+	if rs, ok := step.(wf.Retryable); ok && rs.Retry() != nil {
+		props = append(props, types.WrapHashEntry2(`retry`, rs.Retry()))
+	}
+	var timeout time.Duration
+	if ts, ok := step.(wf.Timed); ok {
+		timeout = ts.Timeout()
+	}
+	if timeout > 0 {
+		props = append(props, types.WrapHashEntry2(`timeout`, types.WrapTimespan(timeout)))
 	}
 
 	name := step.Name()
@@ -230,6 +326,9 @@ func (ds *Builder) createStepDefinition(step wf.Step) serviceapi.Definition {
 		state := step.State()
 		extId := step.ExternalId()
 		ds.RegisterState(name, state)
+		if timeout > 0 {
+			ds.stateTimeouts[name] = timeout
+		}
 		props = append(props, types.WrapHashEntry2(`resourceType`, state.Type()))
 		if extId != `` {
 			props = append(props, types.WrapHashEntry2(`externalId`, types.WrapString(extId)))
@@ -239,6 +338,14 @@ func (ds *Builder) createStepDefinition(step wf.Step) serviceapi.Definition {
 		tn := strings.Title(name)
 		api := step.Interface()
 		ds.RegisterAPI(tn, api)
+		if timeout > 0 {
+			ds.apiTimeouts[tn] = timeout
+		}
+		if stateType := step.HandlerFor(); stateType != nil {
+			ds.types[stateType.Name()] = stateType
+			ds.handlerFor[tn] = stateType
+			props = append(props, types.WrapHashEntry2(`handlerFor`, stateType))
+		}
 		var ifd px.Type
 		if po, ok := api.(px.PuppetObject); ok {
 			ifd = po.PType()
@@ -252,6 +359,9 @@ func (ds *Builder) createStepDefinition(step wf.Step) serviceapi.Definition {
 		api := step.Function()
 		ds.RegisterAPI(tn, api)
 		ds.actionApis[tn] = true
+		if timeout > 0 {
+			ds.apiTimeouts[tn] = timeout
+		}
 		var ifd px.Type
 		if po, ok := api.(px.PuppetObject); ok {
 			ifd = po.PType()
@@ -262,6 +372,9 @@ func (ds *Builder) createStepDefinition(step wf.Step) serviceapi.Definition {
 			}
 		}
 		props = append(props, types.WrapHashEntry2(`interface`, ifd))
+		if undo := step.Undo(); undo != `` {
+			props = append(props, types.WrapHashEntry2(`undo`, types.WrapString(undo)))
+		}
 	case wf.Iterator:
 		style = `iterator`
 		props = append(props, types.WrapHashEntry2(`iterationStyle`, types.WrapString(step.IterationStyle().String())))
@@ -273,7 +386,44 @@ func (ds *Builder) createStepDefinition(step wf.Step) serviceapi.Definition {
 		if step.Into() != `` {
 			props = append(props, types.WrapHashEntry2(`into`, types.WrapString(step.Into())))
 		}
+		if step.MaxIterations() > 0 {
+			props = append(props, types.WrapHashEntry2(`maxIterations`, types.WrapInteger(int64(step.MaxIterations()))))
+		}
+		if step.Parallelism() > 0 {
+			props = append(props, types.WrapHashEntry2(`parallelism`, types.WrapInteger(int64(step.Parallelism()))))
+		}
+		if step.CollectErrors() {
+			props = append(props, types.WrapHashEntry2(`collectErrors`, types.BooleanTrue))
+		}
 		props = append(props, types.WrapHashEntry2(`producer`, ds.createStepDefinition(step.Producer())))
+	case wf.Guard:
+		style = `guard`
+		props = append(props, types.WrapHashEntry2(`body`, ds.createStepDefinition(step.Body())))
+		props = append(props, types.WrapHashEntry2(`handler`, ds.createStepDefinition(step.Handler())))
+	case wf.Wait:
+		style = `wait`
+		if step.Resource() != `` {
+			props = append(props, types.WrapHashEntry2(`resource`, types.WrapString(step.Resource())))
+		} else {
+			props = append(props, types.WrapHashEntry2(`api`, types.WrapString(step.API())))
+			props = append(props, types.WrapHashEntry2(`method`, types.WrapString(step.Method())))
+		}
+		props = append(props, types.WrapHashEntry2(`until`, step.Until()))
+		if step.Interval() > 0 {
+			props = append(props, types.WrapHashEntry2(`interval`, types.WrapTimespan(step.Interval())))
+		}
+	case wf.Switch:
+		style = `switch`
+		cases := make([]px.Value, len(step.Cases()))
+		for i, cs := range step.Cases() {
+			cases[i] = types.WrapHash([]*types.HashEntry{
+				types.WrapHashEntry2(`condition`, cs.Condition),
+				types.WrapHashEntry2(`step`, ds.createStepDefinition(cs.Step))})
+		}
+		props = append(props, types.WrapHashEntry2(`cases`, types.WrapValues(cases)))
+		if dflt := step.Default(); dflt != nil {
+			props = append(props, types.WrapHashEntry2(`default`, ds.createStepDefinition(dflt)))
+		}
 	case wf.Call:
 		style = `call`
 		props = append(props, types.WrapHashEntry2(`call`, types.WrapString(step.Call())))
@@ -450,5 +600,8 @@ func (ds *Builder) Server() *Server {
 		callables[k] = po
 	}
 
-	return &Server{context: ds.ctx, id: ds.serviceId, typeSet: ts, metadata: types.WrapValues(defs), stateConverter: ds.stateConverter, callables: callables, states: ds.states}
+	s := &Server{context: ds.ctx, id: ds.serviceId, typeSet: ts, metadata: types.WrapValues(defs), stateConverter: ds.stateConverter, callables: callables, states: ds.states,
+		apiTimeouts: ds.apiTimeouts, stateTimeouts: ds.stateTimeouts, handlerFor: ds.handlerFor}
+	s.invoker = chainInterceptors(ds.interceptors, s.invoke)
+	return s
 }
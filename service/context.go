@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/serviceapi"
+)
+
+// goContext is a px.Context whose deadline, cancellation, and values are determined by a Go context
+type goContext struct {
+	px.Context
+	goCtx context.Context
+}
+
+// WithContext returns a px.Context that uses the loaders, logger, and variables of the given px.Context
+// but obtains its deadline, cancellation, and values from the given Go context. Values that are not found
+// in the Go context are looked up in the px.Context.
+func WithContext(c px.Context, goCtx context.Context) px.Context {
+	if gc, ok := c.(*goContext); ok {
+		c = gc.Context
+	}
+	return &goContext{c, goCtx}
+}
+
+// WithTimeout returns a px.Context that is canceled when the given timeout elapses or when the
+// returned cancel function is called, whichever happens first.
+func WithTimeout(c px.Context, timeout time.Duration) (px.Context, context.CancelFunc) {
+	goCtx, cancel := context.WithTimeout(c, timeout)
+	return WithContext(c, goCtx), cancel
+}
+
+// WithCancel returns a px.Context that is canceled when the returned cancel function is called.
+func WithCancel(c px.Context) (px.Context, context.CancelFunc) {
+	goCtx, cancel := context.WithCancel(c)
+	return WithContext(c, goCtx), cancel
+}
+
+func (c *goContext) Deadline() (time.Time, bool) {
+	return c.goCtx.Deadline()
+}
+
+func (c *goContext) Done() <-chan struct{} {
+	return c.goCtx.Done()
+}
+
+func (c *goContext) Err() error {
+	return c.goCtx.Err()
+}
+
+func (c *goContext) Value(key interface{}) interface{} {
+	if v := c.goCtx.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// Fork forks the px.Context and retains the Go context so that the fork is canceled
+// together with the receiver.
+func (c *goContext) Fork() px.Context {
+	return &goContext{c.Context.Fork(), c.goCtx}
+}
+
+type invocationIdKey struct{}
+
+// WithInvocationId returns a px.Context that carries the given invocation id. A service client that
+// implements serviceapi.Cancelable will pass the id along with each call made using the returned
+// context so that the call can be canceled.
+func WithInvocationId(c px.Context, invocationId string) px.Context {
+	return WithContext(c, context.WithValue(c, invocationIdKey{}, invocationId))
+}
+
+// InvocationId returns the invocation id carried by the given context or an empty string if
+// the context carries no invocation id.
+func InvocationId(c context.Context) string {
+	if id, ok := c.Value(invocationIdKey{}).(string); ok {
+		return id
+	}
+	return ``
+}
+
+type progressEmitterKey struct{}
+
+// WithProgressEmitter returns a px.Context that carries the given emitter. Handlers invoked using the
+// returned context report their progress to the emitter using EmitProgress.
+func WithProgressEmitter(c px.Context, emitter serviceapi.ProgressEmitter) px.Context {
+	return WithContext(c, context.WithValue(c, progressEmitterKey{}, emitter))
+}
+
+// ProgressEmitter returns the emitter carried by the given context or nil if the context carries no emitter.
+func ProgressEmitter(c context.Context) serviceapi.ProgressEmitter {
+	if emitter, ok := c.Value(progressEmitterKey{}).(serviceapi.ProgressEmitter); ok {
+		return emitter
+	}
+	return nil
+}
+
+// EmitProgress reports progress to the emitter carried by the given context. The progress is discarded
+// when the context carries no emitter.
+func EmitProgress(c context.Context, percent float64, message string, fields px.OrderedMap) {
+	if emitter := ProgressEmitter(c); emitter != nil {
+		emitter(&serviceapi.ProgressEvent{Percent: percent, Message: message, Fields: fields})
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// Steps returns the steps declared by the given service. The steps are reconstructed from the definitions
+// returned by the service Metadata method. Definitions that don't describe a step are ignored.
+func Steps(c px.Context, s serviceapi.Service) []wf.Step {
+	_, defs := s.Metadata(c)
+	steps := make([]wf.Step, 0, len(defs))
+	for _, def := range defs {
+		if IsStepDefinition(def) {
+			steps = append(steps, StepFromDefinition(c, s, def))
+		}
+	}
+	return steps
+}
+
+// StepFromDefinition reconstructs the step described by the given definition. This is the inverse of what the
+// Builder does when it creates a definition from a step.
+//
+// Functions, APIs, and states in the reconstructed step are proxies that invoke the given service, i.e. the
+// service that provided the definition. The reconstructed step can be analyzed, executed, or registered with
+// another Builder in the same way as the step that was used to declare it.
+func StepFromDefinition(c px.Context, s serviceapi.Service, def serviceapi.Definition) wf.Step {
+	step := stepFromDefinition(c, s, def)
+	props := def.Properties()
+	if retry, ok := props.Get5(`retry`, px.Undef).(*wf.RetryPolicy); ok {
+		wf.WithRetry(step, retry)
+	}
+	if timeout, ok := props.Get5(`timeout`, px.Undef).(types.Timespan); ok {
+		wf.WithTimeout(step, timeout.Duration())
+	}
+	return step
+}
+
+func stepFromDefinition(c px.Context, s serviceapi.Service, def serviceapi.Definition) wf.Step {
+	props := def.Properties()
+	name := def.Identifier().Name()
+	origin := issue.ParseLocation(StringProp(props, `origin`))
+	when := wf.ToCondition(props.Get5(`when`, px.Undef))
+	params := ParamsProp(props, `parameters`)
+	returns := ParamsProp(props, `returns`)
+
+	switch style := StringProp(props, `style`); style {
+	case `workflow`:
+		var steps []wf.Step
+		if sl, ok := props.Get4(`steps`); ok {
+			sl.(px.List).Each(func(v px.Value) { steps = append(steps, StepFromDefinition(c, s, v.(serviceapi.Definition))) })
+		}
+		return wf.MakeWorkflow(name, origin, when, params, returns, steps)
+	case `resource`:
+		st := &StateProxy{service: s, name: name, typ: props.Get5(`resourceType`, px.Undef).(px.ObjectType)}
+		return wf.MakeResource(name, origin, when, params, returns, StringProp(props, `externalId`), st)
+	case `stateHandler`:
+		h := wf.MakeStateHandler(name, origin, when, params, returns, newProxy(s, name, props, wf.CrudType))
+		if stateType, ok := props.Get5(`handlerFor`, px.Undef).(px.Type); ok {
+			wf.WithHandlerFor(h, stateType)
+		}
+		return h
+	case `action`:
+		a := wf.MakeAction(name, origin, when, params, returns, newProxy(s, name, props, wf.DoType))
+		return wf.WithUndo(a, StringProp(props, `undo`))
+	case `iterator`:
+		producer := StepFromDefinition(c, s, props.Get5(`producer`, px.Undef).(serviceapi.Definition))
+		it := wf.MakeIterator(name, origin, when, params, returns, wf.NewIterationStyle(StringProp(props, `iterationStyle`)),
+			producer, props.Get5(`over`, px.Undef), ParamsProp(props, `variables`), StringProp(props, `into`))
+		if max, ok := props.Get5(`maxIterations`, px.Undef).(px.Integer); ok {
+			wf.WithMaxIterations(it, int(max.Int()))
+		}
+		if p, ok := props.Get5(`parallelism`, px.Undef).(px.Integer); ok {
+			wf.WithParallelism(it, int(p.Int()))
+		}
+		if ce, ok := props.Get5(`collectErrors`, px.Undef).(px.Boolean); ok {
+			wf.WithCollectErrors(it, ce.Bool())
+		}
+		return it
+	case `guard`:
+		body := StepFromDefinition(c, s, props.Get5(`body`, px.Undef).(serviceapi.Definition))
+		handler := StepFromDefinition(c, s, props.Get5(`handler`, px.Undef).(serviceapi.Definition))
+		return wf.MakeGuard(name, origin, when, params, returns, body, handler)
+	case `wait`:
+		var interval time.Duration
+		if ts, ok := props.Get5(`interval`, px.Undef).(types.Timespan); ok {
+			interval = ts.Duration()
+		}
+		return wf.MakeWait(name, origin, when, params, returns, StringProp(props, `resource`), StringProp(props, `api`),
+			StringProp(props, `method`), wf.ToCondition(props.Get5(`until`, px.Undef)), interval)
+	case `switch`:
+		var cases []wf.Case
+		if cl, ok := props.Get5(`cases`, px.Undef).(px.List); ok {
+			cl.Each(func(v px.Value) {
+				cm := v.(px.OrderedMap)
+				cases = append(cases, wf.Case{
+					Condition: wf.ToCondition(cm.Get5(`condition`, px.Undef)),
+					Step:      StepFromDefinition(c, s, cm.Get5(`step`, px.Undef).(serviceapi.Definition))})
+			})
+		}
+		var dflt wf.Step
+		if dd, ok := props.Get5(`default`, px.Undef).(serviceapi.Definition); ok {
+			dflt = StepFromDefinition(c, s, dd)
+		}
+		return wf.MakeSwitch(name, origin, when, params, returns, cases, dflt)
+	case `call`:
+		return wf.MakeCall(name, origin, when, params, returns, StringProp(props, `call`))
+	default:
+		panic(px.Error(NotAStep, issue.H{`name`: name, `style`: style}))
+	}
+}
+
+// IsStepDefinition returns true if the given definition describes a step, i.e. if a step can be reconstructed
+// from it using StepFromDefinition
+func IsStepDefinition(def serviceapi.Definition) bool {
+	switch StringProp(def.Properties(), `style`) {
+	case `workflow`, `resource`, `stateHandler`, `action`, `iterator`, `guard`, `switch`, `wait`, `call`:
+		return true
+	}
+	return false
+}
+
+// A Proxy is an API that forwards all method calls to an API of a service. It is used as the function of
+// an action and as the interface of a state handler that has been reconstructed from a definition.
+type Proxy struct {
+	service    serviceapi.Service
+	identifier string
+	typ        px.Type
+}
+
+// NewProxy returns a Proxy for the API with the given identifier in the given service. The type describes
+// the methods of that API.
+func NewProxy(s serviceapi.Service, identifier string, typ px.Type) *Proxy {
+	return &Proxy{service: s, identifier: identifier, typ: typ}
+}
+
+func newProxy(s serviceapi.Service, name string, props px.OrderedMap, dflt px.Type) *Proxy {
+	typ := dflt
+	if t, ok := props.Get5(`interface`, px.Undef).(px.Type); ok {
+		typ = t
+	}
+	return NewProxy(s, strings.Title(name), typ)
+}
+
+// Identifier returns the identifier of the proxied API
+func (p *Proxy) Identifier() string {
+	return p.identifier
+}
+
+// Invoke calls the method with the given name on the proxied API
+func (p *Proxy) Invoke(c px.Context, name string, arguments ...px.Value) px.Value {
+	return p.service.Invoke(c, p.identifier, name, arguments...)
+}
+
+// Call implements px.CallableObject so that the proxy can be registered as an API of another service
+func (p *Proxy) Call(c px.Context, method px.ObjFunc, args []px.Value, block px.Lambda) (px.Value, bool) {
+	return p.Invoke(c, method.Name(), args...), true
+}
+
+func (p *Proxy) Equals(other interface{}, guard px.Guard) bool {
+	return p == other
+}
+
+func (p *Proxy) Get(key string) (px.Value, bool) {
+	return nil, false
+}
+
+func (p *Proxy) InitHash() px.OrderedMap {
+	return px.EmptyMap
+}
+
+func (p *Proxy) PType() px.Type {
+	return p.typ
+}
+
+func (p *Proxy) String() string {
+	return px.ToString(p)
+}
+
+func (p *Proxy) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	_, _ = fmt.Fprintf(bld, `%s('%s')`, p.typ.Name(), p.identifier)
+}
+
+// A StateProxy is a wf.State that resolves the state of a resource by calling the State method of a service
+type StateProxy struct {
+	service serviceapi.Service
+	name    string
+	typ     px.ObjectType
+}
+
+// Type returns the type of the resolved state
+func (p *StateProxy) Type() px.ObjectType {
+	return p.typ
+}
+
+// State returns the StateProxy itself
+func (p *StateProxy) State() interface{} {
+	return p
+}
+
+// Resolve resolves the state using the given parameters
+func (p *StateProxy) Resolve(c px.Context, parameters px.OrderedMap) px.PuppetObject {
+	return p.service.State(c, p.name, parameters)
+}
+
+// StringProp returns the string value of the given key in the properties of a definition, or an empty string
+// when the key is missing or its value is not a string
+func StringProp(props px.OrderedMap, key string) string {
+	if s, ok := props.Get5(key, px.Undef).(px.StringValue); ok {
+		return s.String()
+	}
+	return ``
+}
+
+// ParamsProp returns the parameters that are the value of the given key in the properties of a definition, or
+// an empty slice when the key is missing
+func ParamsProp(props px.OrderedMap, key string) []serviceapi.Parameter {
+	if l, ok := props.Get5(key, px.Undef).(px.List); ok {
+		ps := make([]serviceapi.Parameter, l.Len())
+		l.EachWithIndex(func(v px.Value, i int) { ps[i] = v.(serviceapi.Parameter) })
+		return ps
+	}
+	return []serviceapi.Parameter{}
+}
@@ -80,9 +80,13 @@ func newError(c px.Context, message, kind, issueCode string, partialResult px.Va
 }
 
 func errorFromReported(c px.Context, err issue.Reported) serviceapi.ErrorObject {
+	return errorFromReported2(c, `PUPPET_ERROR`, err)
+}
+
+func errorFromReported2(c px.Context, kind string, err issue.Reported) serviceapi.ErrorObject {
 	ev := &errorObj{partialResult: px.Undef, details: px.EmptyMap}
 	ev.message = err.Error()
-	ev.kind = `PUPPET_ERROR`
+	ev.kind = kind
 	ev.issueCode = string(err.Code())
 	ds := make([]*types.HashEntry, 0)
 	if loc := err.Location(); loc != nil {
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/lyraproj/pcore/px"
+)
+
+// StateMethod is the method name that interceptors receive for calls to Server.State
+const StateMethod = `$state`
+
+// Invoker performs a call to Server.Invoke or Server.State
+type Invoker func(c px.Context, api, method string, arguments []px.Value) px.Value
+
+// Interceptor wraps each call to Server.Invoke and Server.State. The interceptor must call next to
+// proceed with the call. It may examine or replace the arguments before doing so, and examine or
+// replace the result afterwards. It can also refuse the call by panicking or by returning an
+// ErrorObject without calling next.
+//
+// For calls to Server.State, the api is the name of the state, the method is StateMethod, and the
+// only argument is the parameters hash.
+type Interceptor func(c px.Context, api, method string, arguments []px.Value, next Invoker) px.Value
+
+// chainInterceptors returns an Invoker that passes each call through the given interceptors before
+// it reaches the given invoker. The first interceptor is the outermost one.
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := invoker
+		invoker = func(c px.Context, api, method string, arguments []px.Value) px.Value {
+			return interceptor(c, api, method, arguments, next)
+		}
+	}
+	return invoker
+}
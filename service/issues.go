@@ -6,21 +6,28 @@ const (
 	AlreadyRegistered    = `WF_ALREADY_REGISTERED`
 	ApiTypeNotRegistered = `WF_API_TYPE_NOT_REGISTERED`
 	IllegalTypeName      = `WF_ILLEGAL_TYPE_NAME`
+	InvocationCanceled   = `WF_INVOCATION_CANCELED`
+	InvocationTimeout    = `WF_INVOCATION_TIMEOUT`
 	NoCommonNamespace    = `WF_NO_COMMON_NAMESPACE`
 	NoSuchApi            = `WF_NO_SUCH_API`
 	NoSuchMethod         = `WF_NO_SUCH_METHOD`
 	NoSuchState          = `WF_NO_SUCH_STATE`
 	NotFound             = `WF_NOT_FOUND`
 	NotFunc              = `WF_NOT_FUNC`
+	NotAStep             = `WF_NOT_A_STEP`
 	NotPuppetObject      = `WF_NOT_PUPPET_OBJECT`
 	NoStateConverter     = `WF_NO_STATE_CONVERTER`
 	TypeNameClash        = `WF_TYPE_NAME_CLASH`
+	UndefinedLoopName    = `WF_UNDEFINED_LOOP_NAME`
+	UndefinedWhenName    = `WF_UNDEFINED_WHEN_NAME`
 )
 
 func init() {
 	issue.Hard(AlreadyRegistered, `the %{namespace} %{identifier} API has already been registered`)
 	issue.Hard(ApiTypeNotRegistered, `the Go type %{type} has not been registered as an API type`)
 	issue.Hard(IllegalTypeName, `name must be segments starting with an uppercase letter joined with'::'. Got: '%{name}'`)
+	issue.Hard(InvocationCanceled, `invocation of %{identifier}/%{name}() was canceled`)
+	issue.Hard(InvocationTimeout, `invocation of %{identifier}/%{name}() did not complete before its deadline`)
 	issue.Hard(NoCommonNamespace, `registered types share no common namespace`)
 	issue.Hard(NoSuchApi, `the '%{api}' API does not exist`)
 	issue.Hard(NoSuchMethod, `the '%{api}' API does not have a method named %{method}`)
@@ -28,6 +35,11 @@ func init() {
 	issue.Hard(NoStateConverter, `no state converter has been registered`)
 	issue.Hard(NotFound, `%{typeName} resource with external id '%{extId}' does not exist`)
 	issue.Hard(NotFunc, `attempt to register a function '%{name}' as a %{type}. Expected a func'`)
+	issue.Hard(NotAStep, `definition %{name} with style '%{style}' does not describe a step`)
 	issue.Hard(NotPuppetObject, `expected resource to produce an Object, got '%{actual}'`)
 	issue.Hard(TypeNameClash, `attempt to register '%{goType}' using both '%{oldType}' and '%{newType}'`)
+	issue.Hard2(UndefinedLoopName, `%{step}: loop condition '%{condition}' refers to '%{name}' which is not returned by %{producer}`,
+		issue.HF{`step`: issue.Label, `producer`: issue.Label})
+	issue.Hard2(UndefinedWhenName, `%{step}: when condition '%{when}' refers to '%{name}' which is not a parameter of the step or of its enclosing step`,
+		issue.HF{`step`: issue.Label})
 }
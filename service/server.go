@@ -1,14 +1,18 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/lyraproj/issue/issue"
 	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/threadlocal"
 	"github.com/lyraproj/pcore/types"
 	"github.com/lyraproj/semver/semver"
 	"github.com/lyraproj/servicesdk/serviceapi"
@@ -25,7 +29,11 @@ type Server struct {
 	metadata       px.List
 	stateConverter wf.StateConverter
 	states         map[string]wf.State
+	apiTimeouts    map[string]time.Duration
+	stateTimeouts  map[string]time.Duration
+	handlerFor     map[string]px.Type
 	callables      map[string]px.Value
+	invoker        Invoker
 }
 
 func (s *Server) AddApi(name string, callable interface{}) serviceapi.Definition {
@@ -61,12 +69,40 @@ func (s *Server) AddApi(name string, callable interface{}) serviceapi.Definition
 }
 
 func (s *Server) State(c px.Context, name string, parameters px.OrderedMap) px.PuppetObject {
+	return s.invoker(c, name, StateMethod, []px.Value{parameters}).(px.PuppetObject)
+}
+
+// invoke is the Invoker at the end of the interceptor chain. The invocation is aborted when it exceeds the
+// timeout declared by the step that the API or state belongs to.
+func (s *Server) invoke(c px.Context, api, name string, arguments []px.Value) px.Value {
+	if name == StateMethod {
+		if timeout, ok := s.stateTimeouts[api]; ok {
+			var cancel context.CancelFunc
+			c, cancel = WithTimeout(c, timeout)
+			defer cancel()
+		}
+		return s.state(c, api, arguments[0].(px.OrderedMap))
+	}
+	if timeout, ok := s.apiTimeouts[api]; ok {
+		var cancel context.CancelFunc
+		c, cancel = WithTimeout(c, timeout)
+		defer cancel()
+	}
+	return s.invokeApi(c, api, name, arguments)
+}
+
+func (s *Server) state(c px.Context, name string, parameters px.OrderedMap) px.PuppetObject {
+	s.lock.RLock()
+	st, ok := s.states[name]
+	s.lock.RUnlock()
+	if rs, isResolvable := st.(wf.ResolvableState); isResolvable {
+		// State of a step that was reconstructed from a definition of another service, or state produced
+		// from a go struct
+		return observe(c, name, `state`, func(c px.Context) px.Value { return rs.Resolve(c, parameters) }).(px.PuppetObject)
+	}
 	if s.stateConverter != nil {
-		s.lock.RLock()
-		st, ok := s.states[name]
-		s.lock.RUnlock()
 		if ok {
-			return s.stateConverter(c, st, parameters)
+			return observe(c, name, `state`, func(c px.Context) px.Value { return s.stateConverter(c, st, parameters) }).(px.PuppetObject)
 		}
 		panic(px.Error(NoSuchState, issue.H{`name`: name}))
 	}
@@ -77,9 +113,16 @@ func (s *Server) Identifier(px.Context) px.TypedName {
 	return s.id
 }
 
-func (s *Server) Invoke(c px.Context, api, name string, arguments ...px.Value) (result px.Value) {
+// Invoke calls the method with the given name on the API registered under the given name. The handler receives
+// the given context and can report its progress to the emitter that it carries using EmitProgress.
+//
+// The call passes through all interceptors registered with Builder.UseInterceptor.
+func (s *Server) Invoke(c px.Context, api, name string, arguments ...px.Value) px.Value {
+	return s.invoker(c, strings.Title(api), name, arguments)
+}
+
+func (s *Server) invokeApi(c px.Context, api, name string, arguments []px.Value) (result px.Value) {
 	s.lock.RLock()
-	api = strings.Title(api)
 	iv, ok := s.callables[api]
 	s.lock.RUnlock()
 	if ok {
@@ -91,14 +134,14 @@ func (s *Server) Invoke(c px.Context, api, name string, arguments ...px.Value) (
 						log.Debug(`Invoke failed`, `error`, x, `stack`, string(debug.Stack()))
 					}
 					if err, ok := x.(issue.Reported); ok && string(err.Code()) == px.GoFunctionError {
-						result = serviceapi.ErrorFromReported(c, err)
+						result = serviceapi.ErrorFromReported(c, s.notFound(api, arguments, err))
 						return
 					}
 					panic(x)
 				}
 			}()
 			log.Debug(`Invoke`, `api`, api, `name`, name)
-			result = m.Call(c, iv, nil, arguments)
+			result = observe(c, api, name, func(c px.Context) px.Value { return m.Call(c, iv, nil, arguments) })
 			return
 		}
 		panic(px.Error(NoSuchMethod, issue.H{`api`: api, `method`: name}))
@@ -106,6 +149,60 @@ func (s *Server) Invoke(c px.Context, api, name string, arguments ...px.Value) (
 	panic(px.Error(NoSuchApi, issue.H{`api`: api}))
 }
 
+// notFound returns the NotFound error when the given error was returned by a handler for a state type and is,
+// or wraps, wf.NotFound. The given error is returned otherwise.
+func (s *Server) notFound(api string, arguments []px.Value, err issue.Reported) issue.Reported {
+	if ge, ok := err.Argument(`error`).(error); ok && errors.Is(ge, wf.NotFound) && len(arguments) > 0 {
+		if stateType, ok := s.handlerFor[api]; ok {
+			return px.Error(NotFound, issue.H{`typeName`: stateType.Name(), `extId`: arguments[0].String()})
+		}
+	}
+	return err
+}
+
+// observe calls the given function with the given context and returns its result. When the given context can be
+// canceled, the function is instead called with a fork of the context in a separate go routine and an ErrorObject
+// is returned as soon as the context is done.
+func observe(c px.Context, identifier, name string, f func(px.Context) px.Value) px.Value {
+	done := c.Done()
+	if done == nil {
+		return f(c)
+	}
+
+	rc := make(chan px.Value, 1)
+	pc := make(chan interface{}, 1)
+	fc := c.Fork()
+	go func() {
+		defer threadlocal.Cleanup()
+		defer func() {
+			if x := recover(); x != nil {
+				pc <- x
+			}
+		}()
+		threadlocal.Init()
+		threadlocal.Set(px.PuppetContextKey, fc)
+		rc <- f(fc)
+	}()
+
+	select {
+	case r := <-rc:
+		return r
+	case x := <-pc:
+		panic(x)
+	case <-done:
+		return abortedError(c, identifier, name)
+	}
+}
+
+// abortedError returns the ErrorObject that describes why the given context is done
+func abortedError(c px.Context, identifier, name string) serviceapi.ErrorObject {
+	args := issue.H{`identifier`: identifier, `name`: name}
+	if c.Err() == context.DeadlineExceeded {
+		return errorFromReported2(c, serviceapi.TimeoutKind, px.Error(InvocationTimeout, args))
+	}
+	return errorFromReported2(c, serviceapi.CanceledKind, px.Error(InvocationCanceled, args))
+}
+
 func (s *Server) Metadata(px.Context) (typeSet px.TypeSet, definitions []serviceapi.Definition) {
 	ds := make([]serviceapi.Definition, s.metadata.Len())
 	s.lock.RLock()
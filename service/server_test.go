@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/lyraproj/pcore/types"
 	"github.com/lyraproj/servicesdk/annotation"
 	"github.com/lyraproj/servicesdk/service"
+	"github.com/lyraproj/servicesdk/serviceapi"
 	"github.com/lyraproj/servicesdk/wf"
 )
 
@@ -46,6 +48,87 @@ func ExampleServer_Invoke() {
 	// second place
 }
 
+func ExampleBuilder_UseInterceptor() {
+	pcore.Do(func(c px.Context) {
+		api := `My::TheApi`
+		sb := service.NewServiceBuilder(c, `My::Service`)
+
+		sb.RegisterAPI(api, &testAPI{})
+		sb.UseInterceptor(func(c px.Context, api, method string, arguments []px.Value, next service.Invoker) px.Value {
+			fmt.Println(`calling`, api, method, arguments)
+			result := next(c, api, method, arguments)
+			fmt.Println(`result`, result)
+			return result
+		})
+		sb.UseInterceptor(func(c px.Context, api, method string, arguments []px.Value, next service.Invoker) px.Value {
+			if method == `first` {
+				return serviceapi.NewError(c, `not allowed`, `ACCESS_DENIED`, ``, nil, nil)
+			}
+			return next(c, api, method, arguments)
+		})
+
+		s := sb.Server()
+		s.Invoke(c, api, `first`)
+		s.Invoke(c, api, `second`, px.Wrap(c, `place`))
+	})
+
+	// Output:
+	// calling My::TheApi first []
+	// result Error('message' => 'not allowed', 'kind' => 'ACCESS_DENIED')
+	// calling My::TheApi second [place]
+	// result second place
+}
+
+type slowAPI struct{}
+
+func (*slowAPI) Sleep(millis int64) string {
+	time.Sleep(time.Duration(millis) * time.Millisecond)
+	return `awake`
+}
+
+func ExampleServer_Invoke_timeout() {
+	pcore.Do(func(c px.Context) {
+		api := `My::SlowApi`
+		sb := service.NewServiceBuilder(c, `My::Service`)
+
+		sb.RegisterAPI(api, &slowAPI{})
+
+		s := sb.Server()
+		tc, cancel := service.WithTimeout(c, 20*time.Millisecond)
+		defer cancel()
+		fmt.Println(s.Invoke(tc, api, `sleep`, px.Wrap(c, 1)))
+		r := s.Invoke(tc, api, `sleep`, px.Wrap(c, 1000))
+		if eo, ok := r.(serviceapi.ErrorObject); ok {
+			fmt.Println(eo.Kind(), eo.IssueCode())
+		}
+	})
+
+	// Output:
+	// awake
+	// TIMEOUT WF_INVOCATION_TIMEOUT
+}
+
+func ExampleServer_Invoke_progress() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Action{
+			Do: func(ctx px.Context) {
+				service.EmitProgress(ctx, 50, `halfway`, nil)
+				service.EmitProgress(ctx, 100, `done`, px.SingletonMap(`items`, px.Wrap(ctx, 3)))
+			}}).Resolve(c, `My::Action`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		s := sb.Server()
+		pc := service.WithProgressEmitter(c, func(e *serviceapi.ProgressEvent) {
+			fmt.Println(e.Percent, e.Message, e.Fields)
+		})
+		s.Invoke(pc, `My::Action`, `do`, px.EmptyMap)
+	})
+
+	// Output:
+	// 50 halfway <nil>
+	// 100 done {'items' => 3}
+}
+
 type MyRes struct {
 	Name  string
 	Phone string
@@ -298,6 +381,59 @@ func ExampleServer_Metadata_definitions() {
 	//
 }
 
+func ExampleBuilder_RegisterStep_undefinedWhenName() {
+	pcore.Do(func(c px.Context) {
+		defer func() {
+			fmt.Println(recover())
+		}()
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Workflow{
+			Parameters: struct{ Env string }{},
+			Steps: map[string]lyra.Step{
+				`deploy`: &lyra.Action{
+					When: `env == 'prod' and aproved`,
+					Do:   func(in struct{ Approved bool }) {}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go, line: 12)`)))
+	})
+
+	// Output: action My::Test::deploy: when condition 'env == 'prod' and aproved' refers to 'aproved' which is not a parameter of the step or of its enclosing step (file: /test/x.go, line: 12)
+}
+
+func ExampleBuilder_RegisterStep_enclosingWhenName() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Workflow{
+			Parameters: struct{ Env string }{},
+			Steps: map[string]lyra.Step{
+				`deploy`: &lyra.Guard{
+					Body:    &lyra.Action{When: `env == 'prod'`, Do: func() {}},
+					Handler: &lyra.Action{Do: func(in struct{ Error px.Value }) {}}},
+				`notify`: &lyra.Switch{
+					Cases:   []lyra.Case{{When: `env == 'test'`, Step: &lyra.Action{When: `env != 'prod'`, Do: func() {}}}},
+					Default: &lyra.Action{Do: func() {}}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go, line: 12)`)))
+		fmt.Println(len(service.Steps(c, sb.Server())))
+	})
+
+	// Output: 1
+}
+
+func ExampleBuilder_RegisterStep_undefinedLoopName() {
+	pcore.Do(func(c px.Context) {
+		defer func() {
+			fmt.Println(recover())
+		}()
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Workflow{
+			Steps: map[string]lyra.Step{
+				`poll`: &lyra.Collect{
+					While: `status != 'ready'`,
+					Step: &lyra.Action{
+						Do: func(in struct{ State string }) struct{ State string } { return in }}}}}).Resolve(
+			c, `My::Test`, issue.ParseLocation(`(file: /test/x.go, line: 12)`)))
+	})
+
+	// Output: iterator My::Test::poll: loop condition 'status != 'ready'' refers to 'status' which is not returned by action My::Test::poll (file: /test/x.go, line: 12)
+}
+
 type OwnerRes struct {
 	Id    *string
 	Phone string
@@ -492,3 +628,175 @@ func ExampleServer_Metadata_api() {
 	// )
 	//
 }
+
+func ExampleSteps() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterTypes("My", &MyRes{})
+		sb.RegisterStateConverter(lyra.StateConverter)
+		sb.RegisterStep((&lyra.Workflow{
+			Parameters: struct{ Name string }{},
+			Steps: map[string]lyra.Step{
+				`greet`: &lyra.Action{
+					When: `name != ''`,
+					Do: func(in struct{ Name string }) struct{ Greeting string } {
+						return struct{ Greeting string }{`hello ` + in.Name}
+					}},
+				`x`: &lyra.Resource{
+					State: func(struct{ Greeting string }) *MyRes {
+						return &MyRes{Name: `Bob`, Phone: `12345`}
+					}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+		remote := sb.Server()
+
+		// Rebuild the steps from the remote metadata and register them with another service
+		hb := service.NewServiceBuilder(c, `My::Host`)
+		for _, step := range service.Steps(c, remote) {
+			fmt.Println(step.Label(), issue.LocationString(step.Origin()))
+			for _, s := range step.(wf.Workflow).Steps() {
+				fmt.Println(` `, s.Label(), `when`, s.When())
+			}
+			hb.RegisterStep(step)
+		}
+
+		host := hb.Server()
+		fmt.Println(host.Invoke(c, `My::Test::Greet`, `do`, px.Wrap(c, map[string]string{`name`: `Alice`})))
+		fmt.Println(host.State(c, `My::Test::x`, px.EmptyMap))
+	})
+
+	// Output:
+	// workflow My::Test (file: /test/x.go)
+	//   action My::Test::greet when name != ''
+	//   resource My::Test::x when true
+	// {'greeting' => 'hello Alice'}
+	// My::MyRes('name' => 'Bob', 'phone' => '12345')
+}
+
+func ExampleBuilder_RegisterStep_retry() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Action{
+			Retry: &wf.RetryPolicy{
+				MaxAttempts: 5,
+				Backoff:     500 * time.Millisecond,
+				MaxBackoff:  10 * time.Second,
+				Jitter:      0.2,
+				Kinds:       []string{serviceapi.TimeoutKind},
+				IssueCodes:  []string{`AWS_THROTTLED`}},
+			Do: func() {}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		s := sb.Server()
+		_, defs := s.Metadata(c)
+		fmt.Println(px.ToPrettyString(defs[0].Properties().Get5(`retry`, px.Undef)))
+		fmt.Println(service.Steps(c, s)[0].(wf.Retryable).Retry().MaxBackoff)
+	})
+
+	// Output:
+	// Lyra::RetryPolicy(
+	//   'maxAttempts' => 5,
+	//   'backoff' => 0-00:00:00.5,
+	//   'maxBackoff' => 0-00:00:10.0,
+	//   'jitter' => 0.20000,
+	//   'kinds' => ['TIMEOUT'],
+	//   'issueCodes' => ['AWS_THROTTLED']
+	// )
+	// 10s
+}
+
+func ExampleServer_Invoke_stepTimeout() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterTypes("My", &MyRes{})
+		sb.RegisterStateConverter(lyra.StateConverter)
+		sb.RegisterStep((&lyra.Workflow{
+			Steps: map[string]lyra.Step{
+				`stuck`: &lyra.Action{
+					Timeout: 20 * time.Millisecond,
+					Do: func(in struct{ Millis int64 }) {
+						time.Sleep(time.Duration(in.Millis) * time.Millisecond)
+					}},
+				`slow`: &lyra.Resource{
+					Timeout: 20 * time.Millisecond,
+					State: func() *MyRes {
+						time.Sleep(time.Second)
+						return &MyRes{Name: `Bob`, Phone: `12345`}
+					}}}}).Resolve(c, `My::Test`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		s := sb.Server()
+		_, defs := s.Metadata(c)
+		defs[0].Properties().Get5(`steps`, px.EmptyArray).(px.List).Each(func(v px.Value) {
+			d := v.(serviceapi.Definition)
+			fmt.Println(d.Identifier().Name(), d.Properties().Get5(`timeout`, px.Undef).(types.Timespan).Duration())
+		})
+
+		fmt.Println(s.Invoke(c, `My::Test::Stuck`, `do`, px.Wrap(c, map[string]int{`millis`: 1})))
+		for _, r := range []px.Value{
+			s.Invoke(c, `My::Test::Stuck`, `do`, px.Wrap(c, map[string]int{`millis`: 1000})),
+			s.State(c, `My::Test::slow`, px.EmptyMap)} {
+			if eo, ok := r.(serviceapi.ErrorObject); ok {
+				fmt.Println(eo.Kind(), eo.IssueCode())
+			}
+		}
+	})
+
+	// Output:
+	// My::Test::slow 20ms
+	// My::Test::stuck 20ms
+	// {}
+	// TIMEOUT WF_INVOCATION_TIMEOUT
+	// TIMEOUT WF_INVOCATION_TIMEOUT
+}
+
+func ExampleServer_Invoke_undo() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Action{
+			Do: func(in struct{ Email string }) struct{ InviteId string } {
+				return struct{ InviteId string }{`inv-` + in.Email}
+			},
+			Undo: func(in struct {
+				Email    string
+				InviteId string
+			}) {
+				fmt.Println(`revoking`, in.InviteId, `sent to`, in.Email)
+			}}).Resolve(c, `My::Invite`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		s := sb.Server()
+		_, defs := s.Metadata(c)
+		fmt.Println(defs[0].Properties().Get5(`undo`, px.Undef))
+
+		args := px.Wrap(c, map[string]string{`email`: `bob@example.com`}).(px.OrderedMap)
+		result := s.Invoke(c, `My::Invite`, `do`, args).(px.OrderedMap)
+		s.Invoke(c, `My::Invite`, `undo`, args.Merge(result))
+	})
+
+	// Output:
+	// undo
+	// revoking inv-bob@example.com sent to bob@example.com
+}
+
+func ExampleServer_Invoke_errorOnlyDo() {
+	pcore.Do(func(c px.Context) {
+		sb := service.NewServiceBuilder(c, `My::Service`)
+		sb.RegisterStep((&lyra.Action{
+			Do: func(in struct{ Fail bool }) error {
+				if in.Fail {
+					return errors.New(`it failed`)
+				}
+				return nil
+			}}).Resolve(c, `My::Check`, issue.ParseLocation(`(file: /test/x.go)`)))
+
+		s := sb.Server()
+		fmt.Println(s.Invoke(c, `My::Check`, `do`, px.SingletonMap(`fail`, types.BooleanFalse)))
+
+		defer func() {
+			if r, ok := recover().(issue.Reported); ok {
+				fmt.Println(r.Code(), r.Cause())
+			}
+		}()
+		s.Invoke(c, `My::Check`, `do`, px.SingletonMap(`fail`, types.BooleanTrue))
+	})
+
+	// Output:
+	// {}
+	// WF_ACTION_EXECUTION_ERROR it failed
+}
@@ -0,0 +1,9 @@
+package serviceapi
+
+import "github.com/lyraproj/pcore/px"
+
+type Cancelable interface {
+	// Cancel aborts the Invoke or State call that was started with a context carrying the given invocation
+	// id. It returns false when no call with that id is in progress.
+	Cancel(c px.Context, invocationId string) bool
+}
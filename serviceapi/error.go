@@ -5,6 +5,14 @@ import (
 	"github.com/lyraproj/pcore/px"
 )
 
+// TimeoutKind is the Kind of the ErrorObject that is returned when an invocation is aborted because
+// the deadline of its context elapsed
+const TimeoutKind = `TIMEOUT`
+
+// CanceledKind is the Kind of the ErrorObject that is returned when an invocation is aborted because
+// its context was canceled
+const CanceledKind = `CANCELED`
+
 type ErrorObject interface {
 	px.PuppetObject
 
@@ -5,5 +5,8 @@ import "github.com/lyraproj/pcore/px"
 type Invokable interface {
 	// Invoke will call a method with the given name on the object identified by the given
 	// identifier and return the result.
+	//
+	// The invocation is aborted when the given context is canceled or when its deadline elapses. The
+	// result is then an ErrorObject of kind CanceledKind or TimeoutKind.
 	Invoke(c px.Context, identifier, name string, arguments ...px.Value) px.Value
 }
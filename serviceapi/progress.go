@@ -0,0 +1,19 @@
+package serviceapi
+
+import "github.com/lyraproj/pcore/px"
+
+// ProgressEvent describes how far an invocation has progressed
+type ProgressEvent struct {
+	// Percent is the percentage done, a value between 0 and 100. A negative value means that
+	// the percentage is unknown
+	Percent float64
+
+	// Message is a human readable description of the current progress
+	Message string
+
+	// Fields are optional structured details of the progress. Will be nil when no details exist
+	Fields px.OrderedMap
+}
+
+// ProgressEmitter is called by handlers to report progress while an invocation is running
+type ProgressEmitter func(event *ProgressEvent)
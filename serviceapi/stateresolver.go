@@ -5,5 +5,8 @@ import "github.com/lyraproj/pcore/px"
 type StateResolver interface {
 	// State looks up a state that has been previously registered with the given name,
 	// resolves it using the given parameters, and returns the created state object.
+	//
+	// The resolution is aborted when the given context is canceled or when its deadline elapses. The
+	// result is then an ErrorObject of kind CanceledKind or TimeoutKind.
 	State(c px.Context, name string, parameters px.OrderedMap) px.PuppetObject
 }
@@ -11,7 +11,11 @@ It has these top-level messages:
 	MetadataResponse
 	InvokeRequest
 	EmptyRequest
+	ProgressEvent
+	InvokeEvent
 	StateRequest
+	CancelRequest
+	CancelResponse
 */
 package servicepb
 
@@ -61,9 +65,10 @@ func (m *MetadataResponse) GetDefinitions() *puppet_datapb.Data {
 }
 
 type InvokeRequest struct {
-	Identifier string              `protobuf:"bytes,1,opt,name=identifier" json:"identifier,omitempty"`
-	Method     string              `protobuf:"bytes,2,opt,name=method" json:"method,omitempty"`
-	Arguments  *puppet_datapb.Data `protobuf:"bytes,3,opt,name=arguments" json:"arguments,omitempty"`
+	Identifier   string              `protobuf:"bytes,1,opt,name=identifier" json:"identifier,omitempty"`
+	Method       string              `protobuf:"bytes,2,opt,name=method" json:"method,omitempty"`
+	Arguments    *puppet_datapb.Data `protobuf:"bytes,3,opt,name=arguments" json:"arguments,omitempty"`
+	InvocationId string              `protobuf:"bytes,4,opt,name=invocation_id,json=invocationId" json:"invocation_id,omitempty"`
 }
 
 func (m *InvokeRequest) Reset()                    { *m = InvokeRequest{} }
@@ -92,6 +97,13 @@ func (m *InvokeRequest) GetArguments() *puppet_datapb.Data {
 	return nil
 }
 
+func (m *InvokeRequest) GetInvocationId() string {
+	if m != nil {
+		return m.InvocationId
+	}
+	return ""
+}
+
 type EmptyRequest struct {
 }
 
@@ -100,15 +112,72 @@ func (m *EmptyRequest) String() string            { return proto.CompactTextStri
 func (*EmptyRequest) ProtoMessage()               {}
 func (*EmptyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+type ProgressEvent struct {
+	Percent float64             `protobuf:"fixed64,1,opt,name=percent" json:"percent,omitempty"`
+	Message string              `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	Fields  *puppet_datapb.Data `protobuf:"bytes,3,opt,name=fields" json:"fields,omitempty"`
+}
+
+func (m *ProgressEvent) Reset()                    { *m = ProgressEvent{} }
+func (m *ProgressEvent) String() string            { return proto.CompactTextString(m) }
+func (*ProgressEvent) ProtoMessage()               {}
+func (*ProgressEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ProgressEvent) GetPercent() float64 {
+	if m != nil {
+		return m.Percent
+	}
+	return 0
+}
+
+func (m *ProgressEvent) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *ProgressEvent) GetFields() *puppet_datapb.Data {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+type InvokeEvent struct {
+	Progress *ProgressEvent      `protobuf:"bytes,1,opt,name=progress" json:"progress,omitempty"`
+	Result   *puppet_datapb.Data `protobuf:"bytes,2,opt,name=result" json:"result,omitempty"`
+}
+
+func (m *InvokeEvent) Reset()                    { *m = InvokeEvent{} }
+func (m *InvokeEvent) String() string            { return proto.CompactTextString(m) }
+func (*InvokeEvent) ProtoMessage()               {}
+func (*InvokeEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *InvokeEvent) GetProgress() *ProgressEvent {
+	if m != nil {
+		return m.Progress
+	}
+	return nil
+}
+
+func (m *InvokeEvent) GetResult() *puppet_datapb.Data {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
 type StateRequest struct {
-	Identifier string              `protobuf:"bytes,1,opt,name=identifier" json:"identifier,omitempty"`
-	Parameters *puppet_datapb.Data `protobuf:"bytes,2,opt,name=parameters" json:"parameters,omitempty"`
+	Identifier   string              `protobuf:"bytes,1,opt,name=identifier" json:"identifier,omitempty"`
+	Parameters   *puppet_datapb.Data `protobuf:"bytes,2,opt,name=parameters" json:"parameters,omitempty"`
+	InvocationId string              `protobuf:"bytes,3,opt,name=invocation_id,json=invocationId" json:"invocation_id,omitempty"`
 }
 
 func (m *StateRequest) Reset()                    { *m = StateRequest{} }
 func (m *StateRequest) String() string            { return proto.CompactTextString(m) }
 func (*StateRequest) ProtoMessage()               {}
-func (*StateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*StateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 func (m *StateRequest) GetIdentifier() string {
 	if m != nil {
@@ -124,11 +193,54 @@ func (m *StateRequest) GetParameters() *puppet_datapb.Data {
 	return nil
 }
 
+func (m *StateRequest) GetInvocationId() string {
+	if m != nil {
+		return m.InvocationId
+	}
+	return ""
+}
+
+type CancelRequest struct {
+	InvocationId string `protobuf:"bytes,1,opt,name=invocation_id,json=invocationId" json:"invocation_id,omitempty"`
+}
+
+func (m *CancelRequest) Reset()                    { *m = CancelRequest{} }
+func (m *CancelRequest) String() string            { return proto.CompactTextString(m) }
+func (*CancelRequest) ProtoMessage()               {}
+func (*CancelRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *CancelRequest) GetInvocationId() string {
+	if m != nil {
+		return m.InvocationId
+	}
+	return ""
+}
+
+type CancelResponse struct {
+	Canceled bool `protobuf:"varint,1,opt,name=canceled" json:"canceled,omitempty"`
+}
+
+func (m *CancelResponse) Reset()                    { *m = CancelResponse{} }
+func (m *CancelResponse) String() string            { return proto.CompactTextString(m) }
+func (*CancelResponse) ProtoMessage()               {}
+func (*CancelResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *CancelResponse) GetCanceled() bool {
+	if m != nil {
+		return m.Canceled
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*MetadataResponse)(nil), "puppet.service.MetadataResponse")
 	proto.RegisterType((*InvokeRequest)(nil), "puppet.service.InvokeRequest")
 	proto.RegisterType((*EmptyRequest)(nil), "puppet.service.EmptyRequest")
+	proto.RegisterType((*ProgressEvent)(nil), "puppet.service.ProgressEvent")
+	proto.RegisterType((*InvokeEvent)(nil), "puppet.service.InvokeEvent")
 	proto.RegisterType((*StateRequest)(nil), "puppet.service.StateRequest")
+	proto.RegisterType((*CancelRequest)(nil), "puppet.service.CancelRequest")
+	proto.RegisterType((*CancelResponse)(nil), "puppet.service.CancelResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -142,8 +254,10 @@ const _ = grpc.SupportPackageIsVersion4
 // Client API for DefinitionService service
 
 type DefinitionServiceClient interface {
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error)
 	Identity(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*puppet_datapb.Data, error)
 	Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*puppet_datapb.Data, error)
+	InvokeStream(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (DefinitionService_InvokeStreamClient, error)
 	Metadata(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*MetadataResponse, error)
 	State(ctx context.Context, in *StateRequest, opts ...grpc.CallOption) (*puppet_datapb.Data, error)
 }
@@ -156,6 +270,15 @@ func NewDefinitionServiceClient(cc *grpc.ClientConn) DefinitionServiceClient {
 	return &definitionServiceClient{cc}
 }
 
+func (c *definitionServiceClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error) {
+	out := new(CancelResponse)
+	err := grpc.Invoke(ctx, "/puppet.service.DefinitionService/Cancel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *definitionServiceClient) Identity(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*puppet_datapb.Data, error) {
 	out := new(puppet_datapb.Data)
 	err := grpc.Invoke(ctx, "/puppet.service.DefinitionService/Identity", in, out, c.cc, opts...)
@@ -174,6 +297,38 @@ func (c *definitionServiceClient) Invoke(ctx context.Context, in *InvokeRequest,
 	return out, nil
 }
 
+func (c *definitionServiceClient) InvokeStream(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (DefinitionService_InvokeStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_DefinitionService_serviceDesc.Streams[0], c.cc, "/puppet.service.DefinitionService/InvokeStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &definitionServiceInvokeStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DefinitionService_InvokeStreamClient interface {
+	Recv() (*InvokeEvent, error)
+	grpc.ClientStream
+}
+
+type definitionServiceInvokeStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *definitionServiceInvokeStreamClient) Recv() (*InvokeEvent, error) {
+	m := new(InvokeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *definitionServiceClient) Metadata(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*MetadataResponse, error) {
 	out := new(MetadataResponse)
 	err := grpc.Invoke(ctx, "/puppet.service.DefinitionService/Metadata", in, out, c.cc, opts...)
@@ -195,8 +350,10 @@ func (c *definitionServiceClient) State(ctx context.Context, in *StateRequest, o
 // Server API for DefinitionService service
 
 type DefinitionServiceServer interface {
+	Cancel(context.Context, *CancelRequest) (*CancelResponse, error)
 	Identity(context.Context, *EmptyRequest) (*puppet_datapb.Data, error)
 	Invoke(context.Context, *InvokeRequest) (*puppet_datapb.Data, error)
+	InvokeStream(*InvokeRequest, DefinitionService_InvokeStreamServer) error
 	Metadata(context.Context, *EmptyRequest) (*MetadataResponse, error)
 	State(context.Context, *StateRequest) (*puppet_datapb.Data, error)
 }
@@ -205,6 +362,24 @@ func RegisterDefinitionServiceServer(s *grpc.Server, srv DefinitionServiceServer
 	s.RegisterService(&_DefinitionService_serviceDesc, srv)
 }
 
+func _DefinitionService_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DefinitionServiceServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/puppet.service.DefinitionService/Cancel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DefinitionServiceServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DefinitionService_Identity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EmptyRequest)
 	if err := dec(in); err != nil {
@@ -241,6 +416,27 @@ func _DefinitionService_Invoke_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DefinitionService_InvokeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InvokeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DefinitionServiceServer).InvokeStream(m, &definitionServiceInvokeStreamServer{stream})
+}
+
+type DefinitionService_InvokeStreamServer interface {
+	Send(*InvokeEvent) error
+	grpc.ServerStream
+}
+
+type definitionServiceInvokeStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *definitionServiceInvokeStreamServer) Send(m *InvokeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _DefinitionService_Metadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EmptyRequest)
 	if err := dec(in); err != nil {
@@ -281,6 +477,10 @@ var _DefinitionService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "puppet.service.DefinitionService",
 	HandlerType: (*DefinitionServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Cancel",
+			Handler:    _DefinitionService_Cancel_Handler,
+		},
 		{
 			MethodName: "Identity",
 			Handler:    _DefinitionService_Identity_Handler,
@@ -298,34 +498,52 @@ var _DefinitionService_serviceDesc = grpc.ServiceDesc{
 			Handler:    _DefinitionService_State_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "InvokeStream",
+			Handler:       _DefinitionService_InvokeStream_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "servicepb/service.proto",
 }
 
 func init() { proto.RegisterFile("servicepb/service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 347 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0x41, 0x4f, 0xf2, 0x40,
-	0x10, 0x05, 0xbe, 0xc0, 0x07, 0x03, 0x1f, 0xf9, 0x58, 0x13, 0x25, 0x44, 0x0d, 0xe9, 0x49, 0x0f,
-	0x6c, 0x23, 0xc6, 0x9b, 0xd1, 0xc4, 0xe0, 0x81, 0x44, 0x2f, 0xe5, 0xa4, 0xb7, 0x96, 0x0e, 0xb0,
-	0xc1, 0xee, 0xae, 0xdd, 0x29, 0x11, 0x7f, 0x8d, 0x3f, 0xd5, 0xd0, 0x16, 0x2c, 0x68, 0xd5, 0x53,
-	0xdb, 0xe9, 0x9b, 0xf7, 0xde, 0xbc, 0x19, 0x38, 0x30, 0x18, 0x2e, 0xc4, 0x18, 0xb5, 0x67, 0xa7,
-	0x6f, 0x5c, 0x87, 0x8a, 0x14, 0x6b, 0xea, 0x48, 0x6b, 0x24, 0x9e, 0x56, 0x3b, 0x2d, 0xdf, 0x25,
-	0x57, 0x7b, 0xf6, 0xea, 0x91, 0x40, 0xac, 0x17, 0xf8, 0x7f, 0x8f, 0xe4, 0xae, 0x2a, 0x0e, 0x1a,
-	0xad, 0xa4, 0x41, 0xd6, 0x83, 0xbf, 0xb4, 0xd4, 0x68, 0x90, 0xda, 0xc5, 0x6e, 0xf1, 0xa4, 0xde,
-	0xdf, 0xe3, 0x29, 0x51, 0xd2, 0xcf, 0x07, 0x2b, 0xf4, 0x1a, 0xc3, 0x2e, 0xa0, 0xee, 0xe3, 0x44,
-	0x48, 0x41, 0x42, 0x49, 0xd3, 0x2e, 0xe5, 0xb7, 0x64, 0x71, 0xd6, 0x2b, 0xfc, 0x1b, 0xca, 0x85,
-	0x9a, 0xa3, 0x83, 0xcf, 0x11, 0x1a, 0x62, 0xc7, 0x00, 0xc2, 0x47, 0x49, 0x62, 0x22, 0x30, 0x8c,
-	0x95, 0x6b, 0x4e, 0xa6, 0xc2, 0xf6, 0xa1, 0x12, 0x20, 0xcd, 0x94, 0x1f, 0x4b, 0xd4, 0x9c, 0xf4,
-	0x8b, 0x9d, 0x41, 0xcd, 0x0d, 0xa7, 0x51, 0x80, 0x92, 0x4c, 0xfb, 0x4f, 0xbe, 0xfa, 0x07, 0xca,
-	0x6a, 0x42, 0xe3, 0x36, 0xd0, 0xb4, 0x4c, 0xa5, 0xad, 0x07, 0x68, 0x8c, 0xc8, 0xa5, 0x5f, 0x5b,
-	0x39, 0x85, 0xb2, 0x90, 0x3a, 0xa2, 0xef, 0x86, 0x4d, 0x10, 0xfd, 0xb7, 0x12, 0xb4, 0x06, 0x9b,
-	0xb1, 0x47, 0xc9, 0x26, 0xd8, 0x35, 0x54, 0x87, 0x31, 0x1d, 0x2d, 0xd9, 0x21, 0xdf, 0x5e, 0x13,
-	0xcf, 0x5a, 0xeb, 0x7c, 0xc5, 0x6d, 0x15, 0xd8, 0x15, 0x54, 0x92, 0xf4, 0xd8, 0xd1, 0x6e, 0xfb,
-	0x56, 0xaa, 0x79, 0xfd, 0x77, 0x50, 0x5d, 0xef, 0xfd, 0x07, 0x03, 0xdd, 0xdd, 0xbf, 0xbb, 0xf7,
-	0x62, 0x15, 0xd8, 0x25, 0x94, 0xe3, 0xfc, 0x3e, 0x53, 0x65, 0x63, 0xcd, 0xf1, 0x72, 0x63, 0x3f,
-	0xf6, 0xa6, 0x82, 0x66, 0x91, 0xc7, 0xc7, 0x2a, 0xb0, 0x13, 0xc8, 0x93, 0xeb, 0x19, 0x7b, 0xaa,
-	0x7a, 0x29, 0x8f, 0xf1, 0xe7, 0xf6, 0xe6, 0xca, 0xbd, 0x4a, 0x7c, 0xbb, 0xe7, 0xef, 0x01, 0x00,
-	0x00, 0xff, 0xff, 0x2f, 0xcf, 0xf2, 0x36, 0xf9, 0x02, 0x00, 0x00,
+	// 529 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xdb, 0x6e, 0xd3, 0x4c,
+	0x10, 0x8e, 0xff, 0xfc, 0xa4, 0xc9, 0xe4, 0x20, 0xba, 0x48, 0x10, 0x05, 0xa8, 0x2a, 0x73, 0x53,
+	0x71, 0x70, 0xa0, 0x85, 0x0b, 0x24, 0x04, 0x12, 0xb4, 0x17, 0x91, 0x00, 0x21, 0xe7, 0x8e, 0x1b,
+	0xb4, 0xb1, 0x27, 0xe9, 0xd2, 0xd8, 0xbb, 0xec, 0x8e, 0x23, 0xf2, 0x06, 0xbc, 0x05, 0x0f, 0xc2,
+	0xcb, 0x21, 0x7b, 0xd7, 0x69, 0xce, 0xe5, 0x2a, 0x99, 0x99, 0x6f, 0x66, 0xbe, 0x9d, 0xf9, 0x3c,
+	0x70, 0xcf, 0xa0, 0x9e, 0x89, 0x08, 0xd5, 0xa8, 0xef, 0xfe, 0x05, 0x4a, 0x4b, 0x92, 0xac, 0xa3,
+	0x32, 0xa5, 0x90, 0x02, 0xe7, 0xed, 0x1d, 0xc6, 0x9c, 0xb8, 0x1a, 0xf5, 0xf3, 0x1f, 0x0b, 0xf1,
+	0x7f, 0xc2, 0xed, 0x4f, 0x48, 0x3c, 0xf7, 0x84, 0x68, 0x94, 0x4c, 0x0d, 0xb2, 0x67, 0x70, 0x40,
+	0x73, 0x85, 0x06, 0xa9, 0xeb, 0x1d, 0x7b, 0x27, 0xcd, 0xd3, 0x3b, 0x81, 0x2b, 0x64, 0xf3, 0x83,
+	0xf3, 0x1c, 0x5d, 0x62, 0xd8, 0x2b, 0x68, 0xc6, 0x38, 0x16, 0xa9, 0x20, 0x21, 0x53, 0xd3, 0xfd,
+	0x6f, 0x77, 0xca, 0x32, 0xce, 0xff, 0xed, 0x41, 0x7b, 0x90, 0xce, 0xe4, 0x15, 0x86, 0xf8, 0x23,
+	0x43, 0x43, 0xec, 0x08, 0x40, 0xc4, 0x98, 0x92, 0x18, 0x0b, 0xd4, 0x45, 0xeb, 0x46, 0xb8, 0xe4,
+	0x61, 0x77, 0xa1, 0x96, 0x20, 0x5d, 0xca, 0xb8, 0xe8, 0xd1, 0x08, 0x9d, 0xc5, 0x5e, 0x40, 0x83,
+	0xeb, 0x49, 0x96, 0x60, 0x4a, 0xa6, 0x5b, 0xdd, 0xdd, 0xfe, 0x1a, 0xc5, 0x1e, 0x41, 0x5b, 0xa4,
+	0x33, 0x19, 0xf1, 0x9c, 0xcb, 0x37, 0x11, 0x77, 0xff, 0x2f, 0x2a, 0xb6, 0xae, 0x9d, 0x83, 0xd8,
+	0xef, 0x40, 0xeb, 0x22, 0x51, 0x34, 0x77, 0xfc, 0x7c, 0x05, 0xed, 0x2f, 0x5a, 0x4e, 0x34, 0x1a,
+	0x73, 0x31, 0xc3, 0x94, 0x58, 0x17, 0x0e, 0x14, 0xea, 0x08, 0x53, 0x3b, 0x28, 0x2f, 0x2c, 0xcd,
+	0x3c, 0x92, 0xa0, 0x31, 0x7c, 0x82, 0x8e, 0x6b, 0x69, 0xb2, 0x27, 0x50, 0x1b, 0x0b, 0x9c, 0xc6,
+	0x7b, 0x99, 0x3a, 0x88, 0x9f, 0x41, 0xd3, 0x8e, 0xc8, 0xf6, 0x7b, 0x0d, 0x75, 0xe5, 0x08, 0xb8,
+	0xcd, 0x3c, 0x0c, 0x56, 0x57, 0x1c, 0xac, 0x10, 0x0c, 0x17, 0xf0, 0xbc, 0xad, 0x46, 0x93, 0x4d,
+	0x69, 0xdf, 0x7e, 0x1c, 0xc4, 0xff, 0xe5, 0x41, 0x6b, 0x48, 0x9c, 0xfe, 0x79, 0x33, 0x67, 0x00,
+	0x8a, 0x6b, 0x9e, 0x20, 0xa1, 0xde, 0xab, 0x80, 0x25, 0xd8, 0xe6, 0x0e, 0xaa, 0x5b, 0x76, 0xf0,
+	0x12, 0xda, 0x1f, 0x78, 0x1a, 0xe1, 0xb4, 0xa4, 0xb2, 0x91, 0xe5, 0x6d, 0xc9, 0x7a, 0x0a, 0x9d,
+	0x32, 0xcb, 0x69, 0xba, 0x07, 0xf5, 0xa8, 0xf0, 0xa0, 0xcd, 0xa8, 0x87, 0x0b, 0xfb, 0xf4, 0x4f,
+	0x15, 0x0e, 0xcf, 0x17, 0xca, 0x1c, 0xda, 0x49, 0xb2, 0x01, 0xd4, 0x6c, 0x0d, 0xb6, 0x31, 0xe4,
+	0x15, 0x46, 0xbd, 0xa3, 0x5d, 0x61, 0xdb, 0xda, 0xaf, 0xb0, 0x77, 0x50, 0x1f, 0x14, 0xc3, 0xa2,
+	0x39, 0x7b, 0xb0, 0x8e, 0x5e, 0x96, 0x58, 0x6f, 0xdb, 0xd0, 0xfc, 0x0a, 0x7b, 0x0b, 0x35, 0xab,
+	0x83, 0x4d, 0x2e, 0x2b, 0x9f, 0xd0, 0xae, 0xfc, 0xcf, 0xd0, 0xb2, 0xb8, 0x21, 0x69, 0xe4, 0xc9,
+	0x4d, 0x55, 0xee, 0x6f, 0x0f, 0x17, 0x9a, 0xf2, 0x2b, 0xcf, 0x3d, 0xf6, 0x11, 0xea, 0xe5, 0xd5,
+	0xb8, 0xe1, 0x41, 0xc7, 0xeb, 0xd1, 0xf5, 0x6b, 0xe3, 0x57, 0xd8, 0x1b, 0xb8, 0x55, 0xa8, 0x6d,
+	0xb3, 0xd4, 0xb2, 0x08, 0x77, 0xbc, 0xed, 0xfd, 0xe3, 0xaf, 0x27, 0x13, 0x41, 0x97, 0xd9, 0x28,
+	0x88, 0x64, 0xd2, 0x9f, 0xce, 0x35, 0x57, 0x5a, 0x7e, 0x2f, 0x2f, 0xa1, 0x89, 0xaf, 0xfa, 0x8b,
+	0xf3, 0x38, 0xaa, 0x15, 0x47, 0xef, 0xec, 0xef, 0x00, 0x25, 0xb5, 0xc1, 0xee, 0x32, 0x05, 0x00,
+	0x00,
 }
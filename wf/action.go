@@ -2,6 +2,7 @@ package wf
 
 import (
 	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
 	"github.com/lyraproj/servicesdk/serviceapi"
 )
 
@@ -9,15 +10,31 @@ type Action interface {
 	Step
 
 	Function() interface{}
+
+	// Undo returns the name of the method of the Function that compensates for the work done by its `do`
+	// method, or an empty string when the action cannot be undone. The compensating method is called with
+	// the parameters and the returns of the `do` invocation that it compensates for.
+	Undo() string
+}
+
+// WithUndo assigns the name of the compensating method to the given action and returns the action.
+func WithUndo(a Action, method string) Action {
+	if us, ok := a.(interface{ setUndo(string) }); ok {
+		us.setUndo(method)
+		return a
+	}
+	panic(px.Error(UnsupportedOption, issue.H{`step`: a, `option`: `undo`}))
 }
 
 type action struct {
 	step
+	timed
 	function interface{}
+	undo     string
 }
 
 func MakeAction(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter, function interface{}) Action {
-	return &action{step{name, origin, when, parameters, returns}, function}
+	return &action{step{name, origin, when, parameters, returns, nil}, timed{}, function, ``}
 }
 
 func (s *action) Label() string {
@@ -27,3 +44,11 @@ func (s *action) Label() string {
 func (s *action) Function() interface{} {
 	return s.function
 }
+
+func (s *action) Undo() string {
+	return s.undo
+}
+
+func (s *action) setUndo(method string) {
+	s.undo = method
+}
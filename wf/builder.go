@@ -1,7 +1,9 @@
 package wf
 
 import (
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/lyraproj/servicesdk/serviceapi"
 
@@ -20,9 +22,12 @@ type Builder interface {
 	Context() px.Context
 	Build() Step
 	Name(string)
+	Origin(issue.Location)
 	When(string)
 	Parameters(...serviceapi.Parameter)
 	Returns(...serviceapi.Parameter)
+	Retry(*RetryPolicy)
+	Timeout(time.Duration)
 	QualifyName(childName string) string
 	GetParameters() []serviceapi.Parameter
 	GetName() string
@@ -38,6 +43,9 @@ type ChildBuilder interface {
 	Call(func(CallBuilder))
 	AddChild(Builder)
 	Iterator(func(IteratorBuilder))
+	Guard(func(GuardBuilder))
+	Switch(func(SwitchBuilder))
+	Wait(func(WaitBuilder))
 }
 
 type APIBuilder interface {
@@ -48,6 +56,9 @@ type APIBuilder interface {
 type StateHandlerBuilder interface {
 	Builder
 	API(interface{})
+
+	// HandlerFor sets the type of the state that is handled
+	HandlerFor(px.Type)
 }
 
 type IteratorBuilder interface {
@@ -56,6 +67,9 @@ type IteratorBuilder interface {
 	Over(px.Value)
 	Variables(...serviceapi.Parameter)
 	Into(into string)
+	MaxIterations(max int)
+	Parallelism(parallelism int)
+	CollectErrors(collectErrors bool)
 }
 
 type ResourceBuilder interface {
@@ -68,6 +82,9 @@ type ResourceBuilder interface {
 type ActionBuilder interface {
 	Builder
 	Doer(interface{})
+
+	// Undoer names the method of the doer that compensates for the work done by its `do` method
+	Undoer(string)
 }
 
 type CallBuilder interface {
@@ -79,6 +96,29 @@ type WorkflowBuilder interface {
 	ChildBuilder
 }
 
+// GuardBuilder builds a Guard. The first child step that is added becomes the body of the guard and the
+// second becomes its handler
+type GuardBuilder interface {
+	ChildBuilder
+}
+
+// SwitchBuilder builds a Switch. Each child step that is added becomes a case of the switch, selected by the
+// condition given in the preceding call to Case. A child step that is not preceded by a call to Case becomes
+// the default of the switch.
+type SwitchBuilder interface {
+	ChildBuilder
+	Case(when string)
+}
+
+// WaitBuilder builds a Wait. Either Resource or API must be given
+type WaitBuilder interface {
+	Builder
+	Resource(name string)
+	API(api, method string)
+	Until(condition string)
+	Interval(time.Duration)
+}
+
 func NewStateHandler(ctx px.Context, bf func(StateHandlerBuilder)) StateHandler {
 	bld := &stateHandlerBuilder{builder: builder{ctx: ctx, when: Always, parameters: noParams, returns: noParams, origin: ctx.StackTop()}}
 	bf(bld)
@@ -109,6 +149,24 @@ func NewCall(ctx px.Context, bf func(CallBuilder)) Call {
 	return bld.Build().(Call)
 }
 
+func NewGuard(ctx px.Context, bf func(GuardBuilder)) Guard {
+	bld := &guardBuilder{childBuilder: childBuilder{builder: builder{ctx: ctx, when: Always, parameters: noParams, returns: noParams, origin: ctx.StackTop()}}}
+	bf(bld)
+	return bld.Build().(Guard)
+}
+
+func NewSwitch(ctx px.Context, bf func(SwitchBuilder)) Switch {
+	bld := &switchBuilder{childBuilder: childBuilder{builder: builder{ctx: ctx, when: Always, parameters: noParams, returns: noParams, origin: ctx.StackTop()}}}
+	bf(bld)
+	return bld.Build().(Switch)
+}
+
+func NewWait(ctx px.Context, bf func(WaitBuilder)) Wait {
+	bld := &waitBuilder{builder: builder{ctx: ctx, when: Always, parameters: noParams, returns: noParams, origin: ctx.StackTop()}}
+	bf(bld)
+	return bld.Build().(Wait)
+}
+
 func NewWorkflow(ctx px.Context, bf func(WorkflowBuilder)) Workflow {
 	bld := &workflowBuilder{childBuilder: childBuilder{builder: builder{ctx: ctx, when: Always, parameters: noParams, returns: noParams, origin: ctx.StackTop()}}}
 	bf(bld)
@@ -122,6 +180,8 @@ type builder struct {
 	when       Condition
 	parameters []serviceapi.Parameter
 	returns    []serviceapi.Parameter
+	retry      *RetryPolicy
+	timeout    time.Duration
 	parent     Builder
 }
 
@@ -133,6 +193,12 @@ func (b *builder) Name(n string) {
 	b.name = n
 }
 
+// Origin sets the location of the step declaration. It defaults to the top of the context stack
+// at the time when the builder was created
+func (b *builder) Origin(origin issue.Location) {
+	b.origin = origin
+}
+
 func (b *builder) When(w string) {
 	if w == `` {
 		b.when = Always
@@ -141,6 +207,26 @@ func (b *builder) When(w string) {
 	}
 }
 
+// Retry sets the policy that controls how the step is retried when it fails
+func (b *builder) Retry(policy *RetryPolicy) {
+	b.retry = policy
+}
+
+// Timeout sets the maximum duration of an invocation of the API or the state of the step. Only the builders
+// of Timed steps accept a timeout.
+func (b *builder) Timeout(timeout time.Duration) {
+	b.timeout = timeout
+}
+
+// options applies the retry policy and timeout of this builder to the given step
+func (b *builder) options(s Step) Step {
+	s = WithRetry(s, b.retry)
+	if b.timeout > 0 {
+		s = WithTimeout(s, b.timeout)
+	}
+	return s
+}
+
 func (b *builder) validate() {
 	if b.name == `` {
 		panic(px.Error(StepNoName, issue.NoArgs))
@@ -188,16 +274,22 @@ func (b *builder) Returns(returns ...serviceapi.Parameter) {
 
 type stateHandlerBuilder struct {
 	builder
-	api interface{}
+	api        interface{}
+	handlerFor px.Type
 }
 
 func (b *stateHandlerBuilder) API(c interface{}) {
 	b.api = c
 }
 
+func (b *stateHandlerBuilder) HandlerFor(stateType px.Type) {
+	b.handlerFor = stateType
+}
+
 func (b *stateHandlerBuilder) Build() Step {
 	b.validate()
-	return MakeStateHandler(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.api)
+	h := MakeStateHandler(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.api)
+	return b.options(WithHandlerFor(h, b.handlerFor))
 }
 
 type childBuilder struct {
@@ -235,16 +327,37 @@ func callChild(b ChildBuilder, bld func(b CallBuilder)) {
 	b.AddChild(ab)
 }
 
+func guardChild(b ChildBuilder, bld func(b GuardBuilder)) {
+	ab := &guardBuilder{childBuilder: childBuilder{builder: builder{parent: b, ctx: b.Context(), when: Always, parameters: noParams, returns: noParams, origin: b.Context().StackTop()}}}
+	bld(ab)
+	b.AddChild(ab)
+}
+
+func switchChild(b ChildBuilder, bld func(b SwitchBuilder)) {
+	ab := &switchBuilder{childBuilder: childBuilder{builder: builder{parent: b, ctx: b.Context(), when: Always, parameters: noParams, returns: noParams, origin: b.Context().StackTop()}}}
+	bld(ab)
+	b.AddChild(ab)
+}
+
+func waitChild(b ChildBuilder, bld func(b WaitBuilder)) {
+	ab := &waitBuilder{builder: builder{parent: b, ctx: b.Context(), when: Always, parameters: noParams, returns: noParams, origin: b.Context().StackTop()}}
+	bld(ab)
+	b.AddChild(ab)
+}
+
 func (b *childBuilder) AddChild(child Builder) {
 	b.children = append(b.children, child.Build())
 }
 
 type iteratorBuilder struct {
 	childBuilder
-	style     IterationStyle
-	over      px.Value
-	variables []serviceapi.Parameter
-	into      string
+	style         IterationStyle
+	over          px.Value
+	variables     []serviceapi.Parameter
+	into          string
+	max           int
+	parallelism   int
+	collectErrors bool
 }
 
 func (b *iteratorBuilder) StateHandler(bld func(b StateHandlerBuilder)) {
@@ -273,6 +386,18 @@ func (b *iteratorBuilder) Iterator(bld func(b IteratorBuilder)) {
 	b.AddChild(ab)
 }
 
+func (b *iteratorBuilder) Guard(bld func(b GuardBuilder)) {
+	guardChild(b, bld)
+}
+
+func (b *iteratorBuilder) Switch(bld func(b SwitchBuilder)) {
+	switchChild(b, bld)
+}
+
+func (b *iteratorBuilder) Wait(bld func(b WaitBuilder)) {
+	waitChild(b, bld)
+}
+
 func (b *iteratorBuilder) GetName() string {
 	if b.name == `` {
 		if len(b.children) != 1 {
@@ -302,6 +427,21 @@ func (b *iteratorBuilder) Into(into string) {
 	b.into = into
 }
 
+// MaxIterations sets the maximum number of iterations of a while or until iterator
+func (b *iteratorBuilder) MaxIterations(max int) {
+	b.max = max
+}
+
+// Parallelism sets the maximum number of iterations that execute concurrently
+func (b *iteratorBuilder) Parallelism(parallelism int) {
+	b.parallelism = parallelism
+}
+
+// CollectErrors sets whether all iterations execute even when some of them fail
+func (b *iteratorBuilder) CollectErrors(collectErrors bool) {
+	b.collectErrors = collectErrors
+}
+
 func (b *iteratorBuilder) Variables(variables ...serviceapi.Parameter) {
 	if len(b.variables) == 0 {
 		b.variables = variables
@@ -312,7 +452,9 @@ func (b *iteratorBuilder) Variables(variables ...serviceapi.Parameter) {
 
 func (b *iteratorBuilder) Build() Step {
 	b.validate()
-	return MakeIterator(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.style, b.children[0], b.over, b.variables, b.into)
+	it := MakeIterator(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.style, b.children[0], b.over, b.variables, b.into)
+	WithParallelism(WithMaxIterations(it, b.max), b.parallelism)
+	return b.options(WithCollectErrors(it, b.collectErrors))
 }
 
 func (b *iteratorBuilder) validate() {
@@ -321,6 +463,193 @@ func (b *iteratorBuilder) validate() {
 	}
 }
 
+type guardBuilder struct {
+	childBuilder
+}
+
+func (b *guardBuilder) Build() Step {
+	b.validate()
+	return b.options(MakeGuard(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.children[0], b.children[1]))
+}
+
+func (b *guardBuilder) validate() {
+	b.builder.validate()
+	if len(b.children) != 2 {
+		panic(px.Error(GuardNotTwoSteps, issue.NoArgs))
+	}
+}
+
+func (b *guardBuilder) StateHandler(bld func(b StateHandlerBuilder)) {
+	stateHandlerChild(b, bld)
+}
+
+func (b *guardBuilder) Resource(bld func(b ResourceBuilder)) {
+	resourceChild(b, bld)
+}
+
+func (b *guardBuilder) Workflow(bld func(b WorkflowBuilder)) {
+	workflowChild(b, bld)
+}
+
+func (b *guardBuilder) Action(bld func(b ActionBuilder)) {
+	actionChild(b, bld)
+}
+
+func (b *guardBuilder) Call(bld func(b CallBuilder)) {
+	callChild(b, bld)
+}
+
+func (b *guardBuilder) Iterator(bld func(b IteratorBuilder)) {
+	ab := &iteratorBuilder{childBuilder: childBuilder{builder: builder{parent: b, ctx: b.ctx, when: Always, parameters: noParams, returns: noParams}}}
+	bld(ab)
+	b.AddChild(ab)
+}
+
+func (b *guardBuilder) Guard(bld func(b GuardBuilder)) {
+	guardChild(b, bld)
+}
+
+func (b *guardBuilder) Switch(bld func(b SwitchBuilder)) {
+	switchChild(b, bld)
+}
+
+func (b *guardBuilder) Wait(bld func(b WaitBuilder)) {
+	waitChild(b, bld)
+}
+
+type switchBuilder struct {
+	childBuilder
+	cases       []Case
+	defaultStep Step
+	next        Condition
+}
+
+func (b *switchBuilder) Build() Step {
+	b.validate()
+	return b.options(MakeSwitch(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.cases, b.defaultStep))
+}
+
+func (b *switchBuilder) validate() {
+	b.builder.validate()
+	if b.next != nil {
+		panic(px.Error(SwitchCaseWithoutStep, issue.H{`condition`: b.next}))
+	}
+	if len(b.cases) == 0 {
+		panic(px.Error(SwitchNoCases, issue.NoArgs))
+	}
+}
+
+// Case sets the condition of the next child step that is added. An error is raised when the condition
+// of the previous case has no step.
+func (b *switchBuilder) Case(when string) {
+	if b.next != nil {
+		panic(px.Error(SwitchCaseWithoutStep, issue.H{`condition`: b.next}))
+	}
+	b.next = Parse(when)
+}
+
+// AddChild adds the given child as a case when its condition has been set using Case and as the default
+// otherwise. An error is raised when the switch already has a default.
+func (b *switchBuilder) AddChild(child Builder) {
+	s := child.Build()
+	switch {
+	case b.next != nil:
+		b.cases = append(b.cases, Case{b.next, s})
+		b.next = nil
+	case b.defaultStep != nil:
+		panic(px.Error(SwitchDuplicateDefault, issue.H{`first`: b.defaultStep.Name(), `second`: s.Name()}))
+	default:
+		b.defaultStep = s
+	}
+}
+
+func (b *switchBuilder) StateHandler(bld func(b StateHandlerBuilder)) {
+	stateHandlerChild(b, bld)
+}
+
+func (b *switchBuilder) Resource(bld func(b ResourceBuilder)) {
+	resourceChild(b, bld)
+}
+
+func (b *switchBuilder) Workflow(bld func(b WorkflowBuilder)) {
+	workflowChild(b, bld)
+}
+
+func (b *switchBuilder) Action(bld func(b ActionBuilder)) {
+	actionChild(b, bld)
+}
+
+func (b *switchBuilder) Call(bld func(b CallBuilder)) {
+	callChild(b, bld)
+}
+
+func (b *switchBuilder) Iterator(bld func(b IteratorBuilder)) {
+	ab := &iteratorBuilder{childBuilder: childBuilder{builder: builder{parent: b, ctx: b.ctx, when: Always, parameters: noParams, returns: noParams}}}
+	bld(ab)
+	b.AddChild(ab)
+}
+
+func (b *switchBuilder) Guard(bld func(b GuardBuilder)) {
+	guardChild(b, bld)
+}
+
+func (b *switchBuilder) Switch(bld func(b SwitchBuilder)) {
+	switchChild(b, bld)
+}
+
+func (b *switchBuilder) Wait(bld func(b WaitBuilder)) {
+	waitChild(b, bld)
+}
+
+type waitBuilder struct {
+	builder
+	resource string
+	api      string
+	method   string
+	until    Condition
+	interval time.Duration
+}
+
+func (b *waitBuilder) Build() Step {
+	b.validate()
+	return b.options(MakeWait(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.resource, b.api, b.method, b.until, b.interval))
+}
+
+func (b *waitBuilder) validate() {
+	b.builder.validate()
+	if b.resource == `` && b.api == `` {
+		panic(px.Error(MissingRequiredField, issue.H{`field`: `resource`}))
+	}
+	if b.until == nil {
+		panic(px.Error(MissingRequiredField, issue.H{`field`: `until`}))
+	}
+}
+
+// Resource sets the name of the resource that is read. The resource is a sibling of the wait so its name is
+// qualified by the parent of the wait.
+func (b *waitBuilder) Resource(name string) {
+	if b.parent != nil {
+		name = b.parent.QualifyName(name)
+	}
+	b.resource = name
+}
+
+// API sets the identifier of the API and the name of the method that is invoked
+func (b *waitBuilder) API(api, method string) {
+	b.api = api
+	b.method = method
+}
+
+// Until sets the condition that must hold for the wait to end
+func (b *waitBuilder) Until(condition string) {
+	b.until = Parse(condition)
+}
+
+// Interval sets the delay between two attempts
+func (b *waitBuilder) Interval(interval time.Duration) {
+	b.interval = interval
+}
+
 type resourceBuilder struct {
 	builder
 	state State
@@ -329,7 +658,7 @@ type resourceBuilder struct {
 
 func (b *resourceBuilder) Build() Step {
 	b.validate()
-	return MakeResource(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.extId, b.state)
+	return b.options(MakeResource(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.extId, b.state))
 }
 
 func (b *resourceBuilder) State(state State) {
@@ -340,17 +669,19 @@ func (b *resourceBuilder) ExternalId(extId string) {
 	b.extId = extId
 }
 
-// RegisterState registers a struct as a state. The state type is inferred from the
-// struct
+// StateStruct sets the state to a struct or a pointer to a struct. The state type is the type that the
+// implementation registry maps to the struct so that type must be registered before the resource is
+// built. See MakeStructState for how the state is resolved.
 func (b *resourceBuilder) StateStruct(state interface{}) {
-	/* TODO: Fix this b.state = newGoState(pt.(px.ObjectType), rv)
-	rv := reflect.ValueOf(state)
-	rt := rv.Type()
+	rt := reflect.TypeOf(state)
+	if rt.Kind() != reflect.Ptr {
+		rt = reflect.PtrTo(rt)
+	}
 	pt, ok := b.ctx.ImplementationRegistry().ReflectedToType(rt)
 	if !ok {
-		pt = b.ctx.Reflector().TypeFromReflect(b.GetName(), nil, rt)
+		panic(px.Error(UnregisteredStateType, issue.H{`step`: b.GetName(), `type`: rt.Elem().String()}))
 	}
-	*/
+	b.state = MakeStructState(pt.(px.ObjectType), state)
 }
 
 type workflowBuilder struct {
@@ -359,7 +690,7 @@ type workflowBuilder struct {
 
 func (b *workflowBuilder) Build() Step {
 	b.validate()
-	return MakeWorkflow(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.children)
+	return b.options(MakeWorkflow(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.children))
 }
 
 func (b *workflowBuilder) StateHandler(bld func(b StateHandlerBuilder)) {
@@ -391,17 +722,23 @@ func (b *workflowBuilder) Iterator(bld func(b IteratorBuilder)) {
 type actionBuilder struct {
 	builder
 	function interface{}
+	undo     string
 }
 
 func (b *actionBuilder) Build() Step {
 	b.validate()
-	return MakeAction(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.function)
+	a := MakeAction(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.function)
+	return b.options(WithUndo(a, b.undo))
 }
 
 func (b *actionBuilder) Doer(d interface{}) {
 	b.function = d
 }
 
+func (b *actionBuilder) Undoer(method string) {
+	b.undo = method
+}
+
 type callBuilder struct {
 	builder
 	calledStep string
@@ -409,9 +746,21 @@ type callBuilder struct {
 
 func (b *callBuilder) Build() Step {
 	b.validate()
-	return MakeCall(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.calledStep)
+	return b.options(MakeCall(b.GetName(), b.origin, b.when, b.parameters, b.returns, b.calledStep))
 }
 
 func (b *callBuilder) CallTo(calledStep string) {
 	b.calledStep = calledStep
 }
+
+func (b *workflowBuilder) Guard(bld func(b GuardBuilder)) {
+	guardChild(b, bld)
+}
+
+func (b *workflowBuilder) Switch(bld func(b SwitchBuilder)) {
+	switchChild(b, bld)
+}
+
+func (b *workflowBuilder) Wait(bld func(b WaitBuilder)) {
+	waitChild(b, bld)
+}
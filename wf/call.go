@@ -18,7 +18,7 @@ type call struct {
 }
 
 func MakeCall(name string, origin issue.Location, when Condition, input, output []serviceapi.Parameter, calledStep string) Call {
-	return &call{step{name, origin, when, input, output}, calledStep}
+	return &call{step{name, origin, when, input, output, nil}, calledStep}
 }
 
 func (s *call) Label() string {
@@ -0,0 +1,291 @@
+package wf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+)
+
+// An Operand is a value used in a comparison or membership Condition
+type Operand interface {
+	fmt.Stringer
+
+	// Resolve returns the value of this operand given the parameters, or false if the value
+	// cannot be found
+	Resolve(parameters px.OrderedMap) (px.Value, bool)
+
+	// Names returns all parameter names in use by this operand. The returned slice is
+	// guaranteed to be unique and sorted alphabetically
+	Names() []string
+}
+
+type literal struct {
+	value px.Value
+}
+
+// Literal returns an Operand that represents the given value
+func Literal(value px.Value) Operand {
+	return &literal{value}
+}
+
+func (l *literal) Resolve(parameters px.OrderedMap) (px.Value, bool) {
+	return l.value, true
+}
+
+func (l *literal) Names() []string {
+	return []string{}
+}
+
+func (l *literal) String() string {
+	if s, ok := l.value.(px.StringValue); ok {
+		return quote(s.String())
+	}
+	return px.ToString(l.value)
+}
+
+type variable string
+
+// Variable returns an Operand that represents the value of the parameter with the given name. The
+// name may contain dots to denote access to attributes or hash entries of the parameter value,
+// e.g. "vpc.tags.name".
+func Variable(name string) Operand {
+	return variable(name)
+}
+
+func (v variable) Resolve(parameters px.OrderedMap) (px.Value, bool) {
+	return resolvePath(parameters, string(v))
+}
+
+func (v variable) Names() []string {
+	return []string{rootName(string(v))}
+}
+
+func (v variable) String() string {
+	return string(v)
+}
+
+type list []Operand
+
+// List returns an Operand that represents a list of the values of the given operands
+func List(operands ...Operand) Operand {
+	return list(operands)
+}
+
+func (l list) Resolve(parameters px.OrderedMap) (px.Value, bool) {
+	vs := make([]px.Value, len(l))
+	for i, o := range l {
+		v, ok := o.Resolve(parameters)
+		if !ok {
+			v = px.Undef
+		}
+		vs[i] = v
+	}
+	return types.WrapValues(vs), true
+}
+
+func (l list) Names() []string {
+	nl := make([]names, len(l))
+	for i, o := range l {
+		nl[i] = o
+	}
+	return mergeAllNames(nl)
+}
+
+func (l list) String() string {
+	b := bytes.NewBufferString(`[`)
+	for i, o := range l {
+		if i > 0 {
+			b.WriteString(`, `)
+		}
+		b.WriteString(o.String())
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// The comparison operators
+const (
+	Equal          = `==`
+	NotEqual       = `!=`
+	Less           = `<`
+	LessOrEqual    = `<=`
+	Greater        = `>`
+	GreaterOrEqual = `>=`
+	In             = `in`
+)
+
+type comparison struct {
+	op  string
+	lhs Operand
+	rhs Operand
+}
+
+// Compare returns a Condition that compares the values of the given operands using the given operator,
+// which must be one of Equal, NotEqual, Less, LessOrEqual, Greater, GreaterOrEqual, or In.
+//
+// Equal and NotEqual compare numbers by value so that 1 == 1.0. The ordering operators yield false
+// unless both values are numbers or both values are strings. In yields true when the right hand
+// value is a list that contains the left hand value, a hash with the left hand value as a key, or a
+// string that contains the left hand string. A missing parameter is compared as undef.
+func Compare(op string, lhs, rhs Operand) Condition {
+	switch op {
+	case Equal, NotEqual, Less, LessOrEqual, Greater, GreaterOrEqual, In:
+		return &comparison{op, lhs, rhs}
+	}
+	panic(px.Error(ConditionInvalidOperator, issue.H{`operator`: op}))
+}
+
+func (c *comparison) IsTrue(parameters px.OrderedMap) bool {
+	lv, ok := c.lhs.Resolve(parameters)
+	if !ok {
+		lv = px.Undef
+	}
+	rv, ok := c.rhs.Resolve(parameters)
+	if !ok {
+		rv = px.Undef
+	}
+	return compare(c.op, lv, rv)
+}
+
+func (c *comparison) Names() []string {
+	return mergeAllNames([]names{c.lhs, c.rhs})
+}
+
+func (c *comparison) Precedence() int {
+	return 4
+}
+
+func (c *comparison) String() string {
+	return c.lhs.String() + ` ` + c.op + ` ` + c.rhs.String()
+}
+
+func compare(op string, lv, rv px.Value) bool {
+	switch op {
+	case Equal:
+		return equals(lv, rv)
+	case NotEqual:
+		return !equals(lv, rv)
+	case In:
+		return contains(rv, lv)
+	}
+
+	var cmp int
+	if ln, ok := lv.(px.Number); ok {
+		rn, ok := rv.(px.Number)
+		if !ok {
+			return false
+		}
+		cmp = compareNumbers(ln, rn)
+	} else if ls, ok := lv.(px.StringValue); ok {
+		rs, ok := rv.(px.StringValue)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(ls.String(), rs.String())
+	} else {
+		return false
+	}
+
+	switch op {
+	case Less:
+		return cmp < 0
+	case LessOrEqual:
+		return cmp <= 0
+	case Greater:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func compareNumbers(a, b px.Number) int {
+	_, af := a.(px.Float)
+	_, bf := b.(px.Float)
+	if af || bf {
+		x, y := a.Float(), b.Float()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	x, y := a.Int(), b.Int()
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func equals(a, b px.Value) bool {
+	if an, ok := a.(px.Number); ok {
+		if bn, ok := b.(px.Number); ok {
+			return compareNumbers(an, bn) == 0
+		}
+		return false
+	}
+	return px.Equals(a, b, nil)
+}
+
+func contains(collection, v px.Value) bool {
+	switch collection := collection.(type) {
+	case px.StringValue:
+		if s, ok := v.(px.StringValue); ok {
+			return strings.Contains(collection.String(), s.String())
+		}
+	case px.OrderedMap:
+		_, ok := collection.Get(v)
+		return ok
+	case px.List:
+		return collection.Any(func(e px.Value) bool { return equals(e, v) })
+	}
+	return false
+}
+
+// resolvePath resolves a possibly dotted name. The first segment is the name of a parameter and
+// each subsequent segment is the name of an attribute or hash entry in the value found so far.
+func resolvePath(parameters px.OrderedMap, path string) (px.Value, bool) {
+	segments := strings.Split(path, `.`)
+	v, ok := parameters.Get4(segments[0])
+	for _, s := range segments[1:] {
+		if !ok {
+			break
+		}
+		switch vv := v.(type) {
+		case px.OrderedMap:
+			v, ok = vv.Get4(s)
+		case px.PuppetObject:
+			v, ok = vv.Get(s)
+		default:
+			ok = false
+		}
+	}
+	return v, ok
+}
+
+func rootName(path string) string {
+	if i := strings.IndexByte(path, '.'); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func quote(s string) string {
+	b := bytes.NewBufferString(`'`)
+	for _, c := range s {
+		if c == '\'' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -2,7 +2,6 @@ package wf
 
 import (
 	"bytes"
-	"fmt"
 	"sort"
 
 	"github.com/lyraproj/pcore/px"
@@ -13,9 +12,11 @@ type boolean bool
 const Always = boolean(true)
 const Never = boolean(false)
 
-// A Condition evaluates to true or false depending on its given parameters
+// A Condition evaluates to true or false depending on its given parameters. A Condition is also a
+// px.PuppetObject so that it can be stored in a step definition. Its String method returns the
+// condition using the syntax understood by Parse.
 type Condition interface {
-	fmt.Stringer
+	px.PuppetObject
 
 	// Precedence returns the operator precedence for this Condition
 	Precedence() int
@@ -23,6 +24,10 @@ type Condition interface {
 	// IsTrue returns true if the given parameters satisfies the condition, false otherwise
 	IsTrue(parameters px.OrderedMap) bool
 
+	// Explain evaluates the condition using the given parameters and returns an Explanation that
+	// describes the outcome of this condition and all of its nested conditions
+	Explain(parameters px.OrderedMap) Explanation
+
 	// Returns all names in use by this condition and its nested conditions. The returned
 	// slice is guaranteed to be unique and sorted alphabetically
 	Names() []string
@@ -55,18 +60,20 @@ func (b boolean) Names() []string {
 type truthy string
 
 // Truthy returns a Condition that yields true when the variable
-// named by the given name contains a truthy value (i.e. not undef or false)
+// named by the given name contains a truthy value (i.e. not undef or false). The
+// name may contain dots to denote access to attributes or hash entries of the
+// variable value.
 func Truthy(name string) Condition {
 	return truthy(name)
 }
 
 func (v truthy) IsTrue(parameters px.OrderedMap) bool {
-	value, ok := parameters.Get4(string(v))
+	value, ok := resolvePath(parameters, string(v))
 	return ok && px.IsTruthy(value)
 }
 
 func (v truthy) Names() []string {
-	return []string{string(v)}
+	return []string{rootName(string(v))}
 }
 
 func (v truthy) Precedence() int {
@@ -101,7 +108,14 @@ func (n *not) Precedence() int {
 
 func (n *not) String() string {
 	b := bytes.NewBufferString(`!`)
-	emitContained(n.condition, n.Precedence(), b)
+	if _, ok := n.condition.(*comparison); ok {
+		// Not strictly necessary but "!(a == b)" is easier to read than "!a == b"
+		b.WriteByte('(')
+		b.WriteString(n.condition.String())
+		b.WriteByte(')')
+	} else {
+		emitContained(n.condition, n.Precedence(), b)
+	}
 	return b.String()
 }
 
@@ -167,9 +181,22 @@ func (o *or) String() string {
 	return concat(o.conditions, o.Precedence(), `or`)
 }
 
+// names is implemented by everything that can report the names that it uses
+type names interface {
+	Names() []string
+}
+
 func mergeNames(conditions []Condition) []string {
+	ns := make([]names, len(conditions))
+	for i, c := range conditions {
+		ns[i] = c
+	}
+	return mergeAllNames(ns)
+}
+
+func mergeAllNames(ns []names) []string {
 	h := make(map[string]bool)
-	for _, c := range conditions {
+	for _, c := range ns {
 		for _, n := range c.Names() {
 			h[n] = true
 		}
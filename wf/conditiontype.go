@@ -0,0 +1,334 @@
+package wf
+
+import (
+	"io"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+)
+
+// The object types of the Condition tree. A Condition is a px.PuppetObject and can therefore be stored in a
+// step definition and be transferred as structured data between services.
+var ConditionMetaType px.ObjectType
+var BooleanConditionMetaType px.ObjectType
+var TruthyConditionMetaType px.ObjectType
+var NotConditionMetaType px.ObjectType
+var AndConditionMetaType px.ObjectType
+var OrConditionMetaType px.ObjectType
+var ComparisonMetaType px.ObjectType
+var VariableMetaType px.ObjectType
+
+func init() {
+	ConditionMetaType = px.NewObjectType(`Lyra::Condition`, `{}`)
+
+	BooleanConditionMetaType = px.NewObjectType(`Lyra::BooleanCondition`, `Lyra::Condition{
+    attributes => {
+      value => Boolean
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		return Boolean(args[0].(px.Boolean).Bool())
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		return Boolean(args[0].(px.OrderedMap).Get5(`value`, types.BooleanFalse).(px.Boolean).Bool())
+	})
+
+	TruthyConditionMetaType = px.NewObjectType(`Lyra::TruthyCondition`, `Lyra::Condition{
+    attributes => {
+      name => String[1]
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		return Truthy(args[0].String())
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		return Truthy(args[0].(px.OrderedMap).Get5(`name`, px.EmptyString).String())
+	})
+
+	NotConditionMetaType = px.NewObjectType(`Lyra::NotCondition`, `Lyra::Condition{
+    attributes => {
+      condition => Lyra::Condition
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		return Not(args[0].(Condition))
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		return Not(args[0].(px.OrderedMap).Get5(`condition`, Always).(Condition))
+	})
+
+	AndConditionMetaType = px.NewObjectType(`Lyra::AndCondition`, `Lyra::Condition{
+    attributes => {
+      conditions => Array[Lyra::Condition]
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		return And(conditionsFromList(args[0]))
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		return And(conditionsFromList(args[0].(px.OrderedMap).Get5(`conditions`, px.EmptyArray)))
+	})
+
+	OrConditionMetaType = px.NewObjectType(`Lyra::OrCondition`, `Lyra::Condition{
+    attributes => {
+      conditions => Array[Lyra::Condition]
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		return Or(conditionsFromList(args[0]))
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		return Or(conditionsFromList(args[0].(px.OrderedMap).Get5(`conditions`, px.EmptyArray)))
+	})
+
+	ComparisonMetaType = px.NewObjectType(`Lyra::Comparison`, `Lyra::Condition{
+    attributes => {
+      operator => Enum['==', '!=', '<', '<=', '>', '>=', 'in'],
+      lhs => RichData,
+      rhs => RichData
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		return Compare(args[0].String(), operandFromValue(args[1]), operandFromValue(args[2]))
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		h := args[0].(px.OrderedMap)
+		return Compare(h.Get5(`operator`, px.EmptyString).String(),
+			operandFromValue(h.Get5(`lhs`, px.Undef)), operandFromValue(h.Get5(`rhs`, px.Undef)))
+	})
+
+	VariableMetaType = px.NewObjectType(`Lyra::Variable`, `{
+    attributes => {
+      name => String[1]
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		return variable(args[0].String())
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		return variable(args[0].(px.OrderedMap).Get5(`name`, px.EmptyString).String())
+	})
+}
+
+// ToCondition converts the given value into a Condition. The value can be a Condition, a string
+// that is parsed into a Condition using Parse, or undef which yields Always.
+func ToCondition(v px.Value) Condition {
+	switch v := v.(type) {
+	case Condition:
+		return v
+	case px.StringValue:
+		return Parse(v.String())
+	}
+	if v == nil || v == px.Undef {
+		return Always
+	}
+	panic(px.Error(FieldTypeMismatch, issue.H{`field`: `when`, `expected`: `Condition`, `actual`: v.PType()}))
+}
+
+func conditionsFromList(v px.Value) []Condition {
+	l := v.(px.List)
+	cs := make([]Condition, l.Len())
+	l.EachWithIndex(func(e px.Value, i int) { cs[i] = e.(Condition) })
+	return cs
+}
+
+// operandFromValue converts a value that has been produced by operandToValue back into an Operand
+func operandFromValue(v px.Value) Operand {
+	switch v := v.(type) {
+	case variable:
+		return v
+	case px.StringValue:
+		return Literal(v)
+	case px.List:
+		ops := make([]Operand, v.Len())
+		v.EachWithIndex(func(e px.Value, i int) { ops[i] = operandFromValue(e) })
+		return List(ops...)
+	}
+	return Literal(v)
+}
+
+// operandToValue converts an Operand into a value that can be stored in a Comparison
+func operandToValue(o Operand) px.Value {
+	switch o := o.(type) {
+	case variable:
+		return o
+	case *literal:
+		return o.value
+	case list:
+		vs := make([]px.Value, len(o))
+		for i, e := range o {
+			vs[i] = operandToValue(e)
+		}
+		return types.WrapValues(vs)
+	}
+	return px.Undef
+}
+
+// Conditions are considered equal when their string representations are equal
+func conditionEquals(c Condition, other interface{}) bool {
+	if oc, ok := other.(Condition); ok {
+		return c.String() == oc.String()
+	}
+	return false
+}
+
+func (b boolean) Equals(other interface{}, guard px.Guard) bool {
+	return conditionEquals(b, other)
+}
+
+func (b boolean) Get(key string) (px.Value, bool) {
+	if key == `value` {
+		return types.WrapBoolean(bool(b)), true
+	}
+	return nil, false
+}
+
+func (b boolean) InitHash() px.OrderedMap {
+	return BooleanConditionMetaType.InstanceHash(b)
+}
+
+func (b boolean) PType() px.Type {
+	return BooleanConditionMetaType
+}
+
+func (b boolean) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(b, format, bld, g)
+}
+
+func (v truthy) Equals(other interface{}, guard px.Guard) bool {
+	return conditionEquals(v, other)
+}
+
+func (v truthy) Get(key string) (px.Value, bool) {
+	if key == `name` {
+		return types.WrapString(string(v)), true
+	}
+	return nil, false
+}
+
+func (v truthy) InitHash() px.OrderedMap {
+	return TruthyConditionMetaType.InstanceHash(v)
+}
+
+func (v truthy) PType() px.Type {
+	return TruthyConditionMetaType
+}
+
+func (v truthy) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(v, format, bld, g)
+}
+
+func (n *not) Equals(other interface{}, guard px.Guard) bool {
+	return conditionEquals(n, other)
+}
+
+func (n *not) Get(key string) (px.Value, bool) {
+	if key == `condition` {
+		return n.condition, true
+	}
+	return nil, false
+}
+
+func (n *not) InitHash() px.OrderedMap {
+	return NotConditionMetaType.InstanceHash(n)
+}
+
+func (n *not) PType() px.Type {
+	return NotConditionMetaType
+}
+
+func (n *not) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(n, format, bld, g)
+}
+
+func conditionList(conditions []Condition) px.List {
+	vs := make([]px.Value, len(conditions))
+	for i, c := range conditions {
+		vs[i] = c
+	}
+	return types.WrapValues(vs)
+}
+
+func (a *and) Equals(other interface{}, guard px.Guard) bool {
+	return conditionEquals(a, other)
+}
+
+func (a *and) Get(key string) (px.Value, bool) {
+	if key == `conditions` {
+		return conditionList(a.conditions), true
+	}
+	return nil, false
+}
+
+func (a *and) InitHash() px.OrderedMap {
+	return AndConditionMetaType.InstanceHash(a)
+}
+
+func (a *and) PType() px.Type {
+	return AndConditionMetaType
+}
+
+func (a *and) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(a, format, bld, g)
+}
+
+func (o *or) Equals(other interface{}, guard px.Guard) bool {
+	return conditionEquals(o, other)
+}
+
+func (o *or) Get(key string) (px.Value, bool) {
+	if key == `conditions` {
+		return conditionList(o.conditions), true
+	}
+	return nil, false
+}
+
+func (o *or) InitHash() px.OrderedMap {
+	return OrConditionMetaType.InstanceHash(o)
+}
+
+func (o *or) PType() px.Type {
+	return OrConditionMetaType
+}
+
+func (o *or) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(o, format, bld, g)
+}
+
+func (c *comparison) Equals(other interface{}, guard px.Guard) bool {
+	return conditionEquals(c, other)
+}
+
+func (c *comparison) Get(key string) (px.Value, bool) {
+	switch key {
+	case `operator`:
+		return types.WrapString(c.op), true
+	case `lhs`:
+		return operandToValue(c.lhs), true
+	case `rhs`:
+		return operandToValue(c.rhs), true
+	}
+	return nil, false
+}
+
+func (c *comparison) InitHash() px.OrderedMap {
+	return ComparisonMetaType.InstanceHash(c)
+}
+
+func (c *comparison) PType() px.Type {
+	return ComparisonMetaType
+}
+
+func (c *comparison) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(c, format, bld, g)
+}
+
+func (v variable) Equals(other interface{}, guard px.Guard) bool {
+	return v == other
+}
+
+func (v variable) Get(key string) (px.Value, bool) {
+	if key == `name` {
+		return types.WrapString(string(v)), true
+	}
+	return nil, false
+}
+
+func (v variable) InitHash() px.OrderedMap {
+	return VariableMetaType.InstanceHash(v)
+}
+
+func (v variable) PType() px.Type {
+	return VariableMetaType
+}
+
+func (v variable) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(v, format, bld, g)
+}
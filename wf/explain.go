@@ -0,0 +1,174 @@
+package wf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lyraproj/pcore/px"
+)
+
+// An Explanation describes the outcome of evaluating a Condition. It is intended to answer questions
+// such as "why was this step skipped?" when a condition combines several clauses.
+type Explanation interface {
+	fmt.Stringer
+
+	// Condition returns the explained condition
+	Condition() Condition
+
+	// Result returns the result of the evaluation. It is always equal to what IsTrue would return
+	// for the same parameters
+	Result() bool
+
+	// Missing returns the names that could not be resolved during the evaluation of the condition and
+	// its nested conditions. A name that contains dots is reported in full. The returned slice is
+	// guaranteed to be unique and sorted alphabetically
+	Missing() []string
+
+	// Explanations returns the explanations of the nested conditions. All nested conditions are
+	// evaluated, even those that didn't affect the result.
+	Explanations() []Explanation
+}
+
+type explanation struct {
+	condition    Condition
+	result       bool
+	missing      []string
+	explanations []Explanation
+}
+
+func (e *explanation) Condition() Condition {
+	return e.condition
+}
+
+func (e *explanation) Result() bool {
+	return e.result
+}
+
+func (e *explanation) Missing() []string {
+	return e.missing
+}
+
+func (e *explanation) Explanations() []Explanation {
+	return e.explanations
+}
+
+// String returns the explanation as an indented tree with one condition per line
+func (e *explanation) String() string {
+	b := bytes.NewBufferString(``)
+	e.appendTo(b, 0)
+	return b.String()
+}
+
+func (e *explanation) appendTo(b *bytes.Buffer, indent int) {
+	if indent > 0 {
+		b.WriteByte('\n')
+	}
+	b.WriteString(strings.Repeat(`  `, indent))
+	if e.result {
+		b.WriteString(`true: `)
+	} else {
+		b.WriteString(`false: `)
+	}
+	b.WriteString(e.condition.String())
+	if len(e.explanations) == 0 && len(e.missing) > 0 {
+		b.WriteString(` (missing: `)
+		b.WriteString(strings.Join(e.missing, `, `))
+		b.WriteByte(')')
+	}
+	for _, c := range e.explanations {
+		c.(*explanation).appendTo(b, indent+1)
+	}
+}
+
+func (b boolean) Explain(parameters px.OrderedMap) Explanation {
+	return &explanation{condition: b, result: bool(b), missing: []string{}}
+}
+
+func (v truthy) Explain(parameters px.OrderedMap) Explanation {
+	value, ok := resolvePath(parameters, string(v))
+	missing := []string{}
+	if !ok {
+		missing = append(missing, string(v))
+	}
+	return &explanation{condition: v, result: ok && px.IsTruthy(value), missing: missing}
+}
+
+func (n *not) Explain(parameters px.OrderedMap) Explanation {
+	ce := n.condition.Explain(parameters)
+	return &explanation{condition: n, result: !ce.Result(), missing: ce.Missing(), explanations: []Explanation{ce}}
+}
+
+func (a *and) Explain(parameters px.OrderedMap) Explanation {
+	es := explainAll(a.conditions, parameters)
+	result := true
+	for _, e := range es {
+		if !e.Result() {
+			result = false
+			break
+		}
+	}
+	return &explanation{condition: a, result: result, missing: mergeMissing(es), explanations: es}
+}
+
+func (o *or) Explain(parameters px.OrderedMap) Explanation {
+	es := explainAll(o.conditions, parameters)
+	result := false
+	for _, e := range es {
+		if e.Result() {
+			result = true
+			break
+		}
+	}
+	return &explanation{condition: o, result: result, missing: mergeMissing(es), explanations: es}
+}
+
+func (c *comparison) Explain(parameters px.OrderedMap) Explanation {
+	missing := make(map[string]bool)
+	missingOperands(c.lhs, parameters, missing)
+	missingOperands(c.rhs, parameters, missing)
+	return &explanation{condition: c, result: c.IsTrue(parameters), missing: sortedKeys(missing)}
+}
+
+func explainAll(conditions []Condition, parameters px.OrderedMap) []Explanation {
+	es := make([]Explanation, len(conditions))
+	for i, c := range conditions {
+		es[i] = c.Explain(parameters)
+	}
+	return es
+}
+
+func mergeMissing(es []Explanation) []string {
+	missing := make(map[string]bool)
+	for _, e := range es {
+		for _, n := range e.Missing() {
+			missing[n] = true
+		}
+	}
+	return sortedKeys(missing)
+}
+
+// missingOperands adds the names of all variables of the given operand that cannot be resolved
+// to the given map
+func missingOperands(o Operand, parameters px.OrderedMap, missing map[string]bool) {
+	switch o := o.(type) {
+	case variable:
+		if _, ok := o.Resolve(parameters); !ok {
+			missing[string(o)] = true
+		}
+	case list:
+		for _, e := range o {
+			missingOperands(e, parameters, missing)
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
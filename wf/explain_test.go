@@ -0,0 +1,32 @@
+package wf
+
+import (
+	"fmt"
+
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+)
+
+func ExampleCondition_Explain() {
+	pcore.Do(func(c px.Context) {
+		params := px.Wrap(c, map[string]interface{}{
+			`env`: `dev`,
+			`vpc`: map[string]interface{}{`tags`: map[string]interface{}{`owner`: `ops`}},
+		}).(px.OrderedMap)
+
+		e := Parse(`!(env in ['test', 'dev']) and vpc.tags.approved or region == 'eu' and !legacy`).Explain(params)
+		fmt.Println(e)
+		fmt.Println(e.Result(), e.Missing())
+	})
+	// Output:
+	// false: !(env in ['test', 'dev']) and vpc.tags.approved or region == 'eu' and !legacy
+	//   false: !(env in ['test', 'dev']) and vpc.tags.approved
+	//     false: !(env in ['test', 'dev'])
+	//       true: env in ['test', 'dev']
+	//     false: vpc.tags.approved (missing: vpc.tags.approved)
+	//   false: region == 'eu' and !legacy
+	//     false: region == 'eu' (missing: region)
+	//     true: !legacy
+	//       false: legacy (missing: legacy)
+	// false [legacy region vpc.tags.approved]
+}
@@ -0,0 +1,257 @@
+package wf
+
+import (
+	"strings"
+
+	"github.com/lyraproj/issue/issue"
+)
+
+// A Graph is the data-flow graph of the steps of a Workflow. A step depends on the step that
+// returns a value for one of its parameters, and a wait for a resource depends on that resource.
+// The parameters of the workflow itself are provided by the caller of the workflow and don't
+// introduce any dependencies.
+type Graph interface {
+	// Workflow returns the workflow that the graph was computed from
+	Workflow() Workflow
+
+	// Producers returns the steps that the given step depends on, i.e. the steps that return
+	// values for the parameters of the given step. The steps are in declaration order.
+	Producers(step Step) []Step
+
+	// Consumers returns the steps that depend on the given step. The steps are in declaration order.
+	Consumers(step Step) []Step
+
+	// Order returns the steps in topological order. A step is always preceded by its producers.
+	// Steps that are part of a dependency cycle are not included.
+	Order() []Step
+
+	// Levels returns the steps grouped so that the steps of a level only depend on steps
+	// in preceding levels. All steps in a level can therefore execute in parallel. Steps
+	// that are part of a dependency cycle are not included.
+	Levels() [][]Step
+
+	// Errors returns the errors found when computing the graph, i.e. dependency cycles, values
+	// that are returned by more than one step, and parameters that no step can provide a value for.
+	Errors() []issue.Reported
+}
+
+type graph struct {
+	workflow  Workflow
+	steps     []Step
+	index     map[Step]int
+	producers [][]int
+	consumers [][]int
+	levels    [][]Step
+	errors    []issue.Reported
+}
+
+// NewGraph computes the data-flow graph of the steps of the given workflow. Parameters and
+// returns are matched by name. The alias of a parameter or return is only in effect inside of
+// the step that declares it and is therefore not considered. Errors are not raised. They are
+// instead made available by the Errors method of the returned Graph.
+func NewGraph(w Workflow) Graph {
+	steps := w.Steps()
+	g := &graph{
+		workflow:  w,
+		steps:     steps,
+		index:     make(map[Step]int, len(steps)),
+		producers: make([][]int, len(steps)),
+		consumers: make([][]int, len(steps)),
+		errors:    make([]issue.Reported, 0)}
+
+	inputs := make(map[string]bool, len(w.Parameters()))
+	for _, p := range w.Parameters() {
+		inputs[p.Name()] = true
+	}
+
+	returnedBy := make(map[string]int)
+	named := make(map[string]int, len(steps))
+	for i, s := range steps {
+		g.index[s] = i
+		named[s.Name()] = i
+		for _, n := range ReturnedNames(s) {
+			if inputs[n] {
+				g.addError(AmbiguousProducer, s, issue.H{`name`: n, `first`: w, `second`: s})
+				continue
+			}
+			if p, found := returnedBy[n]; found {
+				g.addError(AmbiguousProducer, s, issue.H{`name`: n, `first`: steps[p], `second`: s})
+				continue
+			}
+			returnedBy[n] = i
+		}
+	}
+
+	for i, s := range steps {
+		for _, p := range StepParameters(s) {
+			n := p.Name()
+			if pi, found := returnedBy[n]; found && pi != i {
+				g.addEdge(pi, i)
+				continue
+			}
+			if !(inputs[n] || p.Value() != nil) {
+				g.addError(UnresolvedParameter, s, issue.H{`step`: s, `name`: n})
+			}
+		}
+		// A wait for a resource depends on the resource
+		if w, ok := s.(Wait); ok {
+			if ri, found := named[w.Resource()]; found && ri != i {
+				g.addEdge(ri, i)
+			}
+		}
+	}
+	g.computeLevels()
+	return g
+}
+
+func (g *graph) Workflow() Workflow {
+	return g.workflow
+}
+
+func (g *graph) Producers(step Step) []Step {
+	if i, ok := g.index[step]; ok {
+		return g.stepsAt(g.producers[i])
+	}
+	return []Step{}
+}
+
+func (g *graph) Consumers(step Step) []Step {
+	if i, ok := g.index[step]; ok {
+		return g.stepsAt(g.consumers[i])
+	}
+	return []Step{}
+}
+
+func (g *graph) Order() []Step {
+	order := make([]Step, 0, len(g.steps))
+	for _, l := range g.levels {
+		order = append(order, l...)
+	}
+	return order
+}
+
+func (g *graph) Levels() [][]Step {
+	return g.levels
+}
+
+func (g *graph) Errors() []issue.Reported {
+	return g.errors
+}
+
+func (g *graph) addEdge(producer, consumer int) {
+	for _, p := range g.producers[consumer] {
+		if p == producer {
+			return
+		}
+	}
+	g.producers[consumer] = insertSorted(g.producers[consumer], producer)
+	g.consumers[producer] = insertSorted(g.consumers[producer], consumer)
+}
+
+func (g *graph) addError(code issue.Code, step Step, args issue.H) {
+	g.errors = append(g.errors, issue.NewReported(code, issue.SeverityError, args, step.Origin()))
+}
+
+// computeLevels performs a topological sort where each round collects all steps whose
+// producers have been placed in previous rounds. Steps that remain when no more progress
+// can be made are part of, or depend on, a cycle.
+func (g *graph) computeLevels() {
+	n := len(g.steps)
+	pending := make([]int, n)
+	for i := range g.steps {
+		pending[i] = len(g.producers[i])
+	}
+
+	g.levels = make([][]Step, 0)
+	placed := 0
+	level := make([]int, 0)
+	for i := range g.steps {
+		if pending[i] == 0 {
+			level = append(level, i)
+		}
+	}
+	for len(level) > 0 {
+		g.levels = append(g.levels, g.stepsAt(level))
+		placed += len(level)
+		next := make([]int, 0)
+		for _, i := range level {
+			for _, c := range g.consumers[i] {
+				pending[c]--
+				if pending[c] == 0 {
+					next = insertSorted(next, c)
+				}
+			}
+		}
+		level = next
+	}
+
+	if placed < n {
+		g.reportCycles(pending)
+	}
+}
+
+// reportCycles reports one error for each distinct cycle found among the steps that could
+// not be placed by computeLevels.
+func (g *graph) reportCycles(pending []int) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.steps))
+	path := make([]int, 0)
+
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		path = append(path, i)
+		for _, c := range g.consumers[i] {
+			if pending[c] == 0 {
+				continue
+			}
+			switch state[c] {
+			case unvisited:
+				visit(c)
+			case visiting:
+				// Found a cycle. It starts at c in the current path
+				start := 0
+				for path[start] != c {
+					start++
+				}
+				names := make([]string, 0, len(path)-start+1)
+				for _, p := range path[start:] {
+					names = append(names, g.steps[p].Name())
+				}
+				names = append(names, g.steps[c].Name())
+				g.addError(DependencyCycle, g.steps[c], issue.H{`steps`: strings.Join(names, ` -> `)})
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+	}
+
+	for i := range g.steps {
+		if pending[i] > 0 && state[i] == unvisited {
+			visit(i)
+		}
+	}
+}
+
+func (g *graph) stepsAt(is []int) []Step {
+	ss := make([]Step, len(is))
+	for i, x := range is {
+		ss[i] = g.steps[x]
+	}
+	return ss
+}
+
+func insertSorted(is []int, v int) []int {
+	i := len(is)
+	is = append(is, v)
+	for i > 0 && is[i-1] > v {
+		is[i] = is[i-1]
+		i--
+	}
+	is[i] = v
+	return is
+}
@@ -0,0 +1,149 @@
+package wf_test
+
+import (
+	"fmt"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/serviceapi"
+	"github.com/lyraproj/servicesdk/wf"
+
+	// Initialize serviceapi.NewParameter
+	_ "github.com/lyraproj/servicesdk/service"
+)
+
+func params(names ...string) []serviceapi.Parameter {
+	ps := make([]serviceapi.Parameter, len(names))
+	for i, n := range names {
+		ps[i] = serviceapi.NewParameter(n, ``, types.DefaultStringType(), nil)
+	}
+	return ps
+}
+
+func names(ps []serviceapi.Parameter) []string {
+	ns := make([]string, len(ps))
+	for i, p := range ps {
+		ns[i] = p.Name()
+	}
+	return ns
+}
+
+func action(name string, line int, parameters, returns []serviceapi.Parameter) wf.Step {
+	return wf.MakeAction(`test::`+name, issue.NewLocation(`test.yaml`, line, 0), wf.Always, parameters, returns, nil)
+}
+
+func ExampleNewGraph() {
+	pcore.Do(func(c px.Context) {
+		w := wf.MakeWorkflow(`test`, nil, wf.Always, params(`region`), nil, []wf.Step{
+			action(`subnet`, 1, params(`vpcId`), params(`subnetId`)),
+			action(`vpc`, 2, params(`region`), params(`vpcId`)),
+			action(`instance`, 3, []serviceapi.Parameter{
+				serviceapi.NewParameter(`subnetId`, `subnet`, types.DefaultStringType(), nil),
+				serviceapi.NewParameter(`vpcId`, ``, types.DefaultStringType(), nil)}, nil),
+			action(`gateway`, 4, params(`vpcId`), nil),
+		})
+		g := wf.NewGraph(w)
+		for _, l := range g.Levels() {
+			for i, s := range l {
+				if i > 0 {
+					fmt.Print(`, `)
+				}
+				fmt.Print(s.Name())
+			}
+			fmt.Println()
+		}
+		fmt.Println(len(g.Errors()))
+	})
+
+	// Output:
+	// test::vpc
+	// test::subnet, test::gateway
+	// test::instance
+	// 0
+}
+
+func ExampleNewGraph_wait() {
+	pcore.Do(func(c px.Context) {
+		w := wf.NewWorkflow(c, func(b wf.WorkflowBuilder) {
+			b.Name(`test`)
+			b.Wait(func(wb wf.WaitBuilder) {
+				wb.Name(`ready`)
+				wb.Resource(`db`)
+				wb.Until(`status == 'available'`)
+			})
+			b.Call(func(cb wf.CallBuilder) {
+				cb.Name(`db`)
+				cb.CallTo(`My::Database`)
+			})
+		})
+		g := wf.NewGraph(w)
+		fmt.Println(w.Steps()[0].(wf.Wait).Resource())
+		for _, s := range g.Order() {
+			fmt.Println(s.Name())
+		}
+		fmt.Println(len(g.Errors()))
+	})
+
+	// Output:
+	// test::db
+	// test::db
+	// test::ready
+	// 0
+}
+
+func ExampleGraph_Errors() {
+	pcore.Do(func(c px.Context) {
+		w := wf.MakeWorkflow(`test`, nil, wf.Always, nil, nil, []wf.Step{
+			action(`a`, 1, params(`x`), params(`y`)),
+			action(`b`, 2, params(`y`), params(`x`)),
+			action(`c`, 3, params(`z`), params(`y`)),
+		})
+		for _, e := range wf.NewGraph(w).Errors() {
+			fmt.Println(e)
+		}
+	})
+
+	// Output:
+	// value 'y' is provided by both action test::a and action test::c (file: test.yaml, line: 3)
+	// action test::c: no value is provided for parameter 'z' (file: test.yaml, line: 3)
+	// dependency cycle detected: test::a -> test::b -> test::a (file: test.yaml, line: 1)
+}
+
+func ExampleStepParameters() {
+	pcore.Do(func(c px.Context) {
+		fetch := action(`fetch`, 1, nil, params(`host`))
+		g := wf.MakeGuard(`test::g`, nil, wf.Always, nil, nil,
+			action(`g::body`, 2, params(`host`), params(`status`)),
+			action(`g::handler`, 3, params(`host`, wf.GuardErrorParameter), params(`status`)))
+		s := wf.MakeSwitch(`test::s`, nil, wf.Always, nil, nil, []wf.Case{
+			{Condition: wf.Parse(`secure`), Step: action(`s::https`, 4, params(`host`), params(`url`))}},
+			action(`s::http`, 5, params(`port`), params(`url`)))
+		it := wf.MakeIterator(`test::it`, nil, wf.Always, nil, nil, wf.IterationStyleEach,
+			action(`it::ping`, 6, params(`host`, `n`), params(`ok`)), nil, params(`n`), ``)
+
+		for _, st := range []wf.Step{g, s, it} {
+			fmt.Println(st.Name(), names(wf.StepParameters(st)), wf.ReturnedNames(st))
+		}
+
+		w := wf.MakeWorkflow(`test`, nil, wf.Always, params(`secure`, `port`), nil, []wf.Step{g, s, it, fetch})
+		gr := wf.NewGraph(w)
+		for i, l := range gr.Levels() {
+			for _, st := range l {
+				fmt.Println(i, st.Name())
+			}
+		}
+		fmt.Println(len(gr.Errors()))
+	})
+
+	// Output:
+	// test::g [host] [status]
+	// test::s [secure host port] [url]
+	// test::it [host] [it]
+	// 0 test::fetch
+	// 1 test::g
+	// 1 test::s
+	// 1 test::it
+	// 0
+}
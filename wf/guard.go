@@ -0,0 +1,45 @@
+package wf
+
+import (
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/servicesdk/serviceapi"
+)
+
+// GuardErrorParameter is the name of the parameter that the handler of a Guard declares in order to
+// receive the serviceapi.ErrorObject that describes the failure of the body
+const GuardErrorParameter = `error`
+
+// A Guard executes its body and, when the body fails, executes its handler instead. The returns of
+// the guard are produced by the body or, when the body failed, by the handler.
+type Guard interface {
+	Step
+
+	// Body returns the step that is guarded
+	Body() Step
+
+	// Handler returns the step that is executed when the body fails. The handler receives the
+	// serviceapi.ErrorObject that describes the failure in its GuardErrorParameter parameter.
+	Handler() Step
+}
+
+type guard struct {
+	step
+	body    Step
+	handler Step
+}
+
+func MakeGuard(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter, body, handler Step) Guard {
+	return &guard{step{name, origin, when, parameters, returns, nil}, body, handler}
+}
+
+func (g *guard) Label() string {
+	return `guard ` + g.name
+}
+
+func (g *guard) Body() Step {
+	return g.body
+}
+
+func (g *guard) Handler() Step {
+	return g.handler
+}
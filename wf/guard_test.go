@@ -0,0 +1,46 @@
+package wf_test
+
+import (
+	"fmt"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+func ExampleMakeGuard() {
+	pcore.Do(func(c px.Context) {
+		g := wf.MakeGuard(`test::g`, nil, wf.Always, nil, nil,
+			action(`g::body`, 1, params(`host`), params(`status`)),
+			action(`g::handler`, 2, params(`host`, wf.GuardErrorParameter, `retries`), params(`status`)))
+		fmt.Println(g.Label())
+		fmt.Println(g.Body().Name(), g.Handler().Name())
+		fmt.Println(names(wf.StepParameters(g)), wf.ReturnedNames(g))
+	})
+
+	// Output:
+	// guard test::g
+	// test::g::body test::g::handler
+	// [host retries] [status]
+}
+
+func ExampleNewGuard_errors() {
+	pcore.Do(func(c px.Context) {
+		for _, n := range []int{1, 3} {
+			func() {
+				defer func() { fmt.Println(recover().(issue.Reported).Code()) }()
+				wf.NewGuard(c, func(b wf.GuardBuilder) {
+					b.Name(`g`)
+					for i := 0; i < n; i++ {
+						call(b, fmt.Sprint(`s`, i))
+					}
+				})
+			}()
+		}
+	})
+
+	// Output:
+	// WF_GUARD_NOT_TWO_STEPS
+	// WF_GUARD_NOT_TWO_STEPS
+}
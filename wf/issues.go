@@ -4,13 +4,17 @@ import "github.com/lyraproj/issue/issue"
 
 const (
 	ActionExecutionError     = `WF_ACTION_EXECUTION_ERROR`
+	AmbiguousProducer        = `WF_AMBIGUOUS_PRODUCER`
 	BadParameter             = `WF_BAD_PARAMETER`
 	ConditionSyntaxError     = `WF_CONDITION_SYNTAX_ERROR`
 	ConditionMissingRp       = `WF_CONDITION_MISSING_RP`
 	ConditionInvalidName     = `WF_CONDITION_INVALID_NAME`
+	ConditionInvalidOperator = `WF_CONDITION_INVALID_OPERATOR`
 	ConditionUnexpectedEnd   = `WF_CONDITION_UNEXPECTED_END`
+	DependencyCycle          = `WF_DEPENDENCY_CYCLE`
 	ElementNotParameter      = `WF_ELEMENT_NOT_PARAMETER`
 	FieldTypeMismatch        = `WF_FIELD_TYPE_MISMATCH`
+	GuardNotTwoSteps         = `WF_GUARD_NOT_TWO_STEPS`
 	IllegalIterationStyle    = `WF_ILLEGAL_ITERATION_STYLE`
 	IllegalOperation         = `WF_ILLEGAL_OPERATION`
 	InvalidFunction          = `WF_INVALID_FUNCTION`
@@ -25,17 +29,29 @@ const (
 	StepBuildError           = `WF_STEP_BUILD_ERROR`
 	StepNoName               = `WF_STEP_NO_NAME`
 	StateCreationError       = `WF_STATE_CREATION_ERROR`
+	SwitchCaseWithoutStep    = `WF_SWITCH_CASE_WITHOUT_STEP`
+	SwitchDuplicateDefault   = `WF_SWITCH_DUPLICATE_DEFAULT`
+	SwitchNoCases            = `WF_SWITCH_NO_CASES`
+	SwitchReturnsMismatch    = `WF_SWITCH_RETURNS_MISMATCH`
+	UnregisteredStateType    = `WF_UNREGISTERED_STATE_TYPE`
+	UnresolvedParameter      = `WF_UNRESOLVED_PARAMETER`
+	UnsupportedOption        = `WF_UNSUPPORTED_OPTION`
 )
 
 func init() {
 	issue.Hard(ActionExecutionError, `error while executing %{step}`)
+	issue.Hard2(AmbiguousProducer, `value '%{name}' is provided by both %{first} and %{second}`,
+		issue.HF{`first`: issue.Label, `second`: issue.Label})
 	issue.Hard2(BadParameter, `%{step}: element %{name} is not a valid %{parameterType} parameter`, issue.HF{`step`: issue.Label})
 	issue.Hard(ConditionSyntaxError, `syntax error in condition '%{text}' at position %{pos}`)
 	issue.Hard(ConditionMissingRp, `expected right parenthesis in condition '%{text}' at position %{pos}`)
 	issue.Hard(ConditionInvalidName, `invalid name '%{name}' in condition '%{text}' at position %{pos}`)
+	issue.Hard(ConditionInvalidOperator, `invalid condition operator '%{operator}'`)
 	issue.Hard(ConditionUnexpectedEnd, `unexpected end of condition '%{text}' at position %{pos}`)
+	issue.Hard(DependencyCycle, `dependency cycle detected: %{steps}`)
 	issue.Hard(ElementNotParameter, `expected field %{field} element to be a Parameter, got %{type}`)
 	issue.Hard(FieldTypeMismatch, `expected field %{field} to be a %{expected}, got %{actual}`)
+	issue.Hard(GuardNotTwoSteps, `a guard must have exactly two steps, the body and the handler`)
 	issue.Hard(IllegalIterationStyle, `no such iteration style '%{style}'`)
 	issue.Hard(IllegalOperation, `no such operation '%{operation}'`)
 	issue.Hard(InvalidFunction, `invalid function '%{function}'. Expected one of 'create', 'read', 'update', or 'delete'`)
@@ -51,4 +67,12 @@ func init() {
 	issue.Hard(StepBuildError, `error while building %{step}`)
 	issue.Hard(StepNoName, `an step must have a name`)
 	issue.Hard(StateCreationError, `error while creating %{step} state`)
+	issue.Hard(SwitchCaseWithoutStep, `the case '%{condition}' of a switch has no step`)
+	issue.Hard(SwitchDuplicateDefault, `a switch can have only one default step, got %{first} and %{second}`)
+	issue.Hard(SwitchNoCases, `a switch must have at least one case`)
+	issue.Hard2(SwitchReturnsMismatch, `%{step} returns %{returns} but %{switch} returns %{expected}`,
+		issue.HF{`step`: issue.Label, `switch`: issue.Label})
+	issue.Hard(UnregisteredStateType, `the state of resource %{step} is a %{type} but no type is registered for it`)
+	issue.Hard2(UnresolvedParameter, `%{step}: no value is provided for parameter '%{name}'`, issue.HF{`step`: issue.Label})
+	issue.Hard2(UnsupportedOption, `%{step} does not support the %{option} option`, issue.HF{`step`: issue.Label})
 }
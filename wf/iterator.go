@@ -12,6 +12,12 @@ const IterationStyleEach = 1
 const IterationStyleEachPair = 2
 const IterationStyleRange = 3
 const IterationStyleTimes = 4
+const IterationStyleWhile = 5
+const IterationStyleUntil = 6
+
+// DefaultMaxIterations is the maximum number of iterations of a while or until iterator that doesn't
+// declare a maximum
+const DefaultMaxIterations = 1000
 
 func (is IterationStyle) String() string {
 	switch is {
@@ -23,6 +29,10 @@ func (is IterationStyle) String() string {
 		return `range`
 	case IterationStyleTimes:
 		return `times`
+	case IterationStyleWhile:
+		return `while`
+	case IterationStyleUntil:
+		return `until`
 	default:
 		return `unknown iteration style`
 	}
@@ -38,6 +48,10 @@ func NewIterationStyle(style string) IterationStyle {
 		return IterationStyleRange
 	case `times`:
 		return IterationStyleTimes
+	case `while`:
+		return IterationStyleWhile
+	case `until`:
+		return IterationStyleUntil
 	}
 	panic(px.Error(IllegalIterationStyle, issue.H{`style`: style}))
 }
@@ -45,13 +59,16 @@ func NewIterationStyle(style string) IterationStyle {
 type Iterator interface {
 	Step
 
-	// IterationStyle returns the style of iterator, times, range, each, or eachPair.
+	// IterationStyle returns the style of iterator, times, range, each, eachPair, while, or until.
 	IterationStyle() IterationStyle
 
 	// Producer returns the Step that will be invoked once for each iteration
 	Producer() Step
 
-	// Over returns what this iterator will iterate over.
+	// Over returns what this iterator will iterate over. For the while and until styles, this is a
+	// Condition over the returns of the producer that is evaluated after each iteration. Another
+	// iteration runs while the condition is true or until it is true, respectively. The returns of
+	// an iteration are passed as parameters to the next iteration.
 	Over() px.Value
 
 	// Variables returns the variables that this iterator will produce for each iteration. These
@@ -61,20 +78,64 @@ type Iterator interface {
 
 	// Into names the returns from the iteration
 	Into() string
+
+	// MaxIterations returns the maximum number of iterations of a while or until iterator. Zero
+	// means DefaultMaxIterations.
+	MaxIterations() int
+
+	// Parallelism returns the maximum number of iterations that execute concurrently. Zero means
+	// that the number is unlimited.
+	Parallelism() int
+
+	// CollectErrors returns true when all iterations execute even when some of them fail, in which
+	// case the errors of all failed iterations are reported together. When false, the iterator fails
+	// fast, i.e. no new iterations start once an iteration has failed.
+	CollectErrors() bool
+}
+
+// WithMaxIterations assigns the maximum number of iterations to the given iterator and returns the iterator.
+func WithMaxIterations(it Iterator, max int) Iterator {
+	if ms, ok := it.(interface{ setMaxIterations(int) }); ok {
+		ms.setMaxIterations(max)
+		return it
+	}
+	panic(px.Error(UnsupportedOption, issue.H{`step`: it, `option`: `maxIterations`}))
+}
+
+// WithParallelism assigns the maximum number of concurrent iterations to the given iterator and returns the
+// iterator.
+func WithParallelism(it Iterator, parallelism int) Iterator {
+	if ps, ok := it.(interface{ setParallelism(int) }); ok {
+		ps.setParallelism(parallelism)
+		return it
+	}
+	panic(px.Error(UnsupportedOption, issue.H{`step`: it, `option`: `parallelism`}))
+}
+
+// WithCollectErrors assigns the error mode to the given iterator and returns the iterator.
+func WithCollectErrors(it Iterator, collectErrors bool) Iterator {
+	if cs, ok := it.(interface{ setCollectErrors(bool) }); ok {
+		cs.setCollectErrors(collectErrors)
+		return it
+	}
+	panic(px.Error(UnsupportedOption, issue.H{`step`: it, `option`: `collectErrors`}))
 }
 
 type iterator struct {
 	step
-	style     IterationStyle
-	producer  Step
-	over      px.Value
-	variables []serviceapi.Parameter
-	into      string
+	style         IterationStyle
+	producer      Step
+	over          px.Value
+	variables     []serviceapi.Parameter
+	into          string
+	maxIterations int
+	parallelism   int
+	collectErrors bool
 }
 
 func MakeIterator(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter,
 	style IterationStyle, producer Step, over px.Value, variables []serviceapi.Parameter, into string) Iterator {
-	return &iterator{step{name, origin, when, parameters, returns}, style, producer, over, variables, into}
+	return &iterator{step{name, origin, when, parameters, returns, nil}, style, producer, over, variables, into, 0, 0, false}
 }
 
 func (it *iterator) Label() string {
@@ -100,3 +161,27 @@ func (it *iterator) Into() string {
 func (it *iterator) Variables() []serviceapi.Parameter {
 	return it.variables
 }
+
+func (it *iterator) MaxIterations() int {
+	return it.maxIterations
+}
+
+func (it *iterator) setMaxIterations(max int) {
+	it.maxIterations = max
+}
+
+func (it *iterator) Parallelism() int {
+	return it.parallelism
+}
+
+func (it *iterator) setParallelism(parallelism int) {
+	it.parallelism = parallelism
+}
+
+func (it *iterator) CollectErrors() bool {
+	return it.collectErrors
+}
+
+func (it *iterator) setCollectErrors(collectErrors bool) {
+	it.collectErrors = collectErrors
+}
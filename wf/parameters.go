@@ -0,0 +1,118 @@
+package wf
+
+import (
+	"github.com/lyraproj/pcore/types"
+	"github.com/lyraproj/servicesdk/serviceapi"
+)
+
+// StepParameters returns the parameters that must be provided to the given step by its enclosing
+// workflow. A step that doesn't declare any parameters derives them from the steps that it contains:
+//
+// An Iterator uses the parameters of its producer, except the loop parameters of a while or until
+// iterator. A Guard uses the parameters of its body and of its handler, except GuardErrorParameter.
+// A Switch uses the names in the conditions of its cases and the parameters of its cases and default.
+//
+// The iteration variables of an Iterator are never included.
+func StepParameters(step Step) []serviceapi.Parameter {
+	ps := step.Parameters()
+	if len(ps) == 0 {
+		ps = containedParameters(step)
+	}
+	if it, ok := step.(Iterator); ok && len(it.Variables()) > 0 {
+		vs := make(map[string]bool, len(it.Variables()))
+		for _, v := range it.Variables() {
+			vs[v.Name()] = true
+		}
+		fps := make([]serviceapi.Parameter, 0, len(ps))
+		for _, p := range ps {
+			if !vs[p.Name()] {
+				fps = append(fps, p)
+			}
+		}
+		ps = fps
+	}
+	return ps
+}
+
+// containedParameters derives the parameters of a step that doesn't declare any from the steps that it contains
+func containedParameters(step Step) []serviceapi.Parameter {
+	var ps []serviceapi.Parameter
+	switch step := step.(type) {
+	case Iterator:
+		seen := make(map[string]bool)
+		if is := step.IterationStyle(); is == IterationStyleWhile || is == IterationStyleUntil {
+			for _, n := range LoopParameters(step.Producer()) {
+				seen[n] = true
+			}
+		}
+		ps = appendUnseen(ps, seen, StepParameters(step.Producer()))
+	case Guard:
+		seen := map[string]bool{GuardErrorParameter: true}
+		ps = appendUnseen(ps, seen, StepParameters(step.Body()))
+		ps = appendUnseen(ps, seen, StepParameters(step.Handler()))
+	case Switch:
+		seen := make(map[string]bool)
+		for _, c := range step.Cases() {
+			for _, n := range c.Condition.Names() {
+				ps = appendUnseen(ps, seen, []serviceapi.Parameter{serviceapi.NewParameter(n, ``, types.DefaultAnyType(), nil)})
+			}
+			ps = appendUnseen(ps, seen, StepParameters(c.Step))
+		}
+		if d := step.Default(); d != nil {
+			ps = appendUnseen(ps, seen, StepParameters(d))
+		}
+	}
+	return ps
+}
+
+// appendUnseen appends the parameters that have names that are not yet seen to the given slice
+func appendUnseen(ps []serviceapi.Parameter, seen map[string]bool, more []serviceapi.Parameter) []serviceapi.Parameter {
+	for _, p := range more {
+		if !seen[p.Name()] {
+			seen[p.Name()] = true
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// ReturnedNames returns the names of the values that the given step makes available to the other steps of
+// its enclosing workflow. An Iterator that doesn't declare any returns makes its result available under the
+// name given by Into, or under its leaf name when Into is empty. A Guard that doesn't declare any returns
+// makes the returns of its body available.
+func ReturnedNames(step Step) []string {
+	rs := step.Returns()
+	if len(rs) == 0 {
+		switch step := step.(type) {
+		case Iterator:
+			if into := step.Into(); into != `` {
+				return []string{into}
+			}
+			return []string{LeafName(step.Name())}
+		case Guard:
+			return ReturnedNames(step.Body())
+		}
+	}
+	names := make([]string, len(rs))
+	for i, r := range rs {
+		names[i] = r.Name()
+	}
+	return names
+}
+
+// LoopParameters returns the names of the parameters of the given producer of a while or until iterator that
+// are also returned by the producer. The value of such a parameter is undef in the first iteration unless it
+// is provided by the iterator.
+func LoopParameters(producer Step) []string {
+	returned := make(map[string]bool)
+	for _, n := range ReturnedNames(producer) {
+		returned[n] = true
+	}
+	names := make([]string, 0)
+	for _, p := range StepParameters(producer) {
+		if returned[p.Name()] {
+			names = append(names, p.Name())
+		}
+	}
+	return names
+}
@@ -1,12 +1,15 @@
 package wf
 
 import (
+	"bytes"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/scanner"
 
 	"github.com/lyraproj/issue/issue"
 	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
 )
 
 var namePattern = regexp.MustCompile(`\A[a-z][a-zA-Z0-9_]*\z`)
@@ -16,6 +19,16 @@ type parser struct {
 	scn scanner.Scanner
 }
 
+// Parse parses the given string into a Condition. The string may contain:
+//
+// - names of parameters, optionally followed by dotted access to attributes or hash entries, e.g. "vpc.tags.env"
+// - string literals in single or double quotes, number literals, and the boolean literals true and false
+// - list literals, e.g. "['prod', 'staging']"
+// - the comparison operators ==, !=, <, <=, >, >=, and in
+// - the logical operators !, and, or
+// - parentheses
+//
+// A name that is not part of a comparison yields true when its value is truthy.
 func Parse(str string) Condition {
 	if str == `` {
 		return Always
@@ -23,6 +36,10 @@ func Parse(str string) Condition {
 	p := &parser{}
 	p.str = str
 	p.scn.Init(strings.NewReader(str))
+	p.scn.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats | scanner.ScanStrings | scanner.ScanRawStrings
+	p.scn.Error = func(s *scanner.Scanner, msg string) {
+		panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: s.Offset}))
+	}
 	c, r := p.parseOr()
 	if r != scanner.EOF {
 		panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
@@ -80,17 +97,155 @@ func (p *parser) parseAtom(r rune) (Condition, rune) {
 		}
 		return c, p.scn.Scan()
 	}
-	w := p.scn.TokenText()
-	if namePattern.MatchString(w) {
-		r = p.scn.Scan()
+
+	pos := p.scn.Offset
+	var lhs Operand
+	lhs, r = p.parseOperand(r)
+	if op := p.parseOperator(r); op != `` {
+		var rhs Operand
+		rhs, r = p.parseOperand(p.scn.Scan())
+		return Compare(op, lhs, rhs), r
+	}
+
+	switch lhs := lhs.(type) {
+	case variable:
+		return Truthy(string(lhs)), r
+	case *literal:
+		if b, ok := lhs.value.(px.Boolean); ok {
+			return Boolean(b.Bool()), r
+		}
+	}
+	panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: pos}))
+}
+
+// parseOperator returns the comparison operator that starts with the given rune or an empty string
+// if the rune doesn't start an operator. Multi character operators are consumed in full.
+func (p *parser) parseOperator(r rune) string {
+	switch r {
+	case '=':
+		if p.scn.Peek() == '=' {
+			p.scn.Next()
+			return Equal
+		}
+		panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+	case '!':
+		if p.scn.Peek() == '=' {
+			p.scn.Next()
+			return NotEqual
+		}
+	case '<':
+		if p.scn.Peek() == '=' {
+			p.scn.Next()
+			return LessOrEqual
+		}
+		return Less
+	case '>':
+		if p.scn.Peek() == '=' {
+			p.scn.Next()
+			return GreaterOrEqual
+		}
+		return Greater
+	case scanner.Ident:
+		if p.scn.TokenText() == In {
+			return In
+		}
+	}
+	return ``
+}
+
+// parseOperand parses a literal, a list, or a possibly dotted variable name
+func (p *parser) parseOperand(r rune) (Operand, rune) {
+	switch r {
+	case scanner.EOF:
+		panic(px.Error(ConditionUnexpectedEnd, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+	case scanner.Ident:
+		w := p.scn.TokenText()
 		switch w {
 		case `true`:
-			return Always, r
+			return Literal(types.WrapBoolean(true)), p.scn.Scan()
 		case `false`:
-			return Never, r
-		default:
-			return Truthy(w), r
+			return Literal(types.WrapBoolean(false)), p.scn.Scan()
+		}
+		if !namePattern.MatchString(w) {
+			panic(px.Error(ConditionInvalidName, issue.H{`name`: w, `text`: p.str, `pos`: p.scn.Offset}))
+		}
+		for p.scn.Peek() == '.' {
+			p.scn.Next()
+			if p.scn.Scan() != scanner.Ident {
+				panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+			}
+			w += `.` + p.scn.TokenText()
+		}
+		return Variable(w), p.scn.Scan()
+	case scanner.Int, scanner.Float:
+		return p.parseNumber(``), p.scn.Scan()
+	case '-':
+		r = p.scn.Scan()
+		if r == scanner.Int || r == scanner.Float {
+			return p.parseNumber(`-`), p.scn.Scan()
+		}
+	case scanner.String, scanner.RawString:
+		s, err := strconv.Unquote(p.scn.TokenText())
+		if err != nil {
+			panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+		}
+		return Literal(types.WrapString(s)), p.scn.Scan()
+	case '\'':
+		return Literal(types.WrapString(p.parseSingleQuoted())), p.scn.Scan()
+	case '[':
+		ops := make([]Operand, 0)
+		r = p.scn.Scan()
+		if r == ']' {
+			return List(ops...), p.scn.Scan()
+		}
+		for {
+			var o Operand
+			o, r = p.parseOperand(r)
+			ops = append(ops, o)
+			switch r {
+			case ',':
+				r = p.scn.Scan()
+			case ']':
+				return List(ops...), p.scn.Scan()
+			case scanner.EOF:
+				panic(px.Error(ConditionUnexpectedEnd, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+			default:
+				panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+			}
+		}
+	}
+	panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+}
+
+func (p *parser) parseNumber(sign string) Operand {
+	t := sign + p.scn.TokenText()
+	if i, err := strconv.ParseInt(t, 10, 64); err == nil {
+		return Literal(types.WrapInteger(i))
+	}
+	f, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		panic(px.Error(ConditionSyntaxError, issue.H{`text`: p.str, `pos`: p.scn.Offset}))
+	}
+	return Literal(types.WrapFloat(f))
+}
+
+// parseSingleQuoted reads the characters of a single quoted string. The opening quote has
+// already been consumed. A backslash escapes the character that follows it.
+func (p *parser) parseSingleQuoted() string {
+	b := bytes.NewBufferString(``)
+	for {
+		c := p.scn.Next()
+		switch c {
+		case scanner.EOF:
+			panic(px.Error(ConditionUnexpectedEnd, issue.H{`text`: p.str, `pos`: len(p.str)}))
+		case '\'':
+			return b.String()
+		case '\\':
+			c = p.scn.Next()
+			if c == scanner.EOF {
+				panic(px.Error(ConditionUnexpectedEnd, issue.H{`text`: p.str, `pos`: len(p.str)}))
+			}
 		}
+		b.WriteRune(c)
 	}
-	panic(px.Error(ConditionInvalidName, issue.H{`name`: w, `text`: p.str, `pos`: p.scn.Offset}))
 }
@@ -38,3 +38,57 @@ func ExampleParse_or() {
 	})
 	// Output: greeting and (hello or goodbye)
 }
+
+func ExampleParse_comparison() {
+	pcore.Do(func(c px.Context) {
+		cond := Parse(`env == "prod" and replicas >= 3 or !(region in ['eu-west-1', 'eu-north-1'])`)
+		fmt.Println(cond)
+		fmt.Println(cond.Names())
+	})
+	// Output:
+	// env == 'prod' and replicas >= 3 or !(region in ['eu-west-1', 'eu-north-1'])
+	// [env region replicas]
+}
+
+func ExampleParse_decimal() {
+	pcore.Do(func(c px.Context) {
+		fmt.Println(Parse(`replicas == 010`))
+	})
+	// Output: replicas == 10
+}
+
+func ExampleCondition_IsTrue() {
+	pcore.Do(func(c px.Context) {
+		params := px.Wrap(c, map[string]interface{}{
+			`env`:      `prod`,
+			`replicas`: 3,
+			`vpc`:      map[string]interface{}{`tags`: map[string]interface{}{`owner`: `ops`}},
+		}).(px.OrderedMap)
+
+		for _, s := range []string{
+			`env == 'prod'`,
+			`env != 'prod'`,
+			`replicas > 2.5`,
+			`replicas < -1`,
+			`replicas == 3.0`,
+			`env in ['test', 'prod']`,
+			`vpc.tags.owner == 'ops'`,
+			`vpc.tags.name`,
+			`'o' in vpc.tags.owner`,
+			`missing == false`,
+		} {
+			fmt.Println(s, `=>`, Parse(s).IsTrue(params))
+		}
+	})
+	// Output:
+	// env == 'prod' => true
+	// env != 'prod' => false
+	// replicas > 2.5 => true
+	// replicas < -1 => false
+	// replicas == 3.0 => true
+	// env in ['test', 'prod'] => true
+	// vpc.tags.owner == 'ops' => true
+	// vpc.tags.name => false
+	// 'o' in vpc.tags.owner => true
+	// missing == false => false
+}
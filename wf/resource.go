@@ -23,12 +23,13 @@ type Resource interface {
 
 type resource struct {
 	step
+	timed
 	state State
 	extId string
 }
 
 func MakeResource(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter, extId string, state State) Resource {
-	return &resource{step{name, origin, when, parameters, returns}, state, extId}
+	return &resource{step{name, origin, when, parameters, returns, nil}, timed{}, state, extId}
 }
 
 func (r *resource) ExternalId() string {
@@ -0,0 +1,195 @@
+package wf
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+)
+
+// A RetryPolicy controls how a failed step is retried by the executor of a workflow
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts. Zero means no maximum
+	MaxBackoff time.Duration
+
+	// Multiplier is multiplied with the delay after each retry. Zero means 2
+	Multiplier float64
+
+	// Jitter is the fraction of the delay, between 0 and 1, that is randomized
+	Jitter float64
+
+	// Kinds are the error kinds that are retryable. Errors of all kinds are retryable unless
+	// Kinds or IssueCodes are given
+	Kinds []string
+
+	// IssueCodes are the issue codes of errors that are retryable. Errors with all issue codes
+	// are retryable unless Kinds or IssueCodes are given
+	IssueCodes []string
+}
+
+var RetryPolicyMetaType px.ObjectType
+
+func init() {
+	RetryPolicyMetaType = px.NewObjectType(`Lyra::RetryPolicy`, `{
+    attributes => {
+      maxAttempts => Integer[1],
+      backoff => { type => Optional[Timespan], value => undef },
+      maxBackoff => { type => Optional[Timespan], value => undef },
+      multiplier => { type => Optional[Float[0.0]], value => undef },
+      jitter => { type => Optional[Float[0.0, 1.0]], value => undef },
+      kinds => { type => Optional[Array[String]], value => undef },
+      issueCodes => { type => Optional[Array[String]], value => undef }
+    }
+  }`, func(ctx px.Context, args []px.Value) px.Value {
+		p := &RetryPolicy{MaxAttempts: int(args[0].(px.Integer).Int())}
+		for i, n := range retryPolicyOptionals {
+			if i+1 < len(args) {
+				p.set(n, args[i+1])
+			}
+		}
+		return p
+	}, func(ctx px.Context, args []px.Value) px.Value {
+		h := args[0].(px.OrderedMap)
+		p := &RetryPolicy{MaxAttempts: int(h.Get5(`maxAttempts`, types.WrapInteger(1)).(px.Integer).Int())}
+		for _, n := range retryPolicyOptionals {
+			p.set(n, h.Get5(n, px.Undef))
+		}
+		return p
+	})
+}
+
+var retryPolicyOptionals = []string{`backoff`, `maxBackoff`, `multiplier`, `jitter`, `kinds`, `issueCodes`}
+
+func (p *RetryPolicy) set(key string, v px.Value) {
+	if v == px.Undef {
+		return
+	}
+	switch key {
+	case `backoff`:
+		p.Backoff = v.(types.Timespan).Duration()
+	case `maxBackoff`:
+		p.MaxBackoff = v.(types.Timespan).Duration()
+	case `multiplier`:
+		p.Multiplier = v.(px.Number).Float()
+	case `jitter`:
+		p.Jitter = v.(px.Number).Float()
+	case `kinds`:
+		p.Kinds = stringsFromList(v)
+	case `issueCodes`:
+		p.IssueCodes = stringsFromList(v)
+	}
+}
+
+// Delay returns the delay before the given retry. The first retry is retry number 1.
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	m := p.Multiplier
+	if m == 0 {
+		m = 2
+	}
+	d := float64(p.Backoff) * math.Pow(m, float64(retry-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// Retryable returns true if an error with the given kind and issue code should be retried
+func (p *RetryPolicy) Retryable(kind, issueCode string) bool {
+	if len(p.Kinds) == 0 && len(p.IssueCodes) == 0 {
+		return true
+	}
+	for _, k := range p.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	for _, c := range p.IssueCodes {
+		if c == issueCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) Equals(other interface{}, guard px.Guard) bool {
+	if op, ok := other.(*RetryPolicy); ok {
+		return p.InitHash().Equals(op.InitHash(), guard)
+	}
+	return false
+}
+
+func (p *RetryPolicy) Get(key string) (px.Value, bool) {
+	switch key {
+	case `maxAttempts`:
+		return types.WrapInteger(int64(p.MaxAttempts)), true
+	case `backoff`:
+		return durationOrUndef(p.Backoff), true
+	case `maxBackoff`:
+		return durationOrUndef(p.MaxBackoff), true
+	case `multiplier`:
+		return floatOrUndef(p.Multiplier), true
+	case `jitter`:
+		return floatOrUndef(p.Jitter), true
+	case `kinds`:
+		return stringsOrUndef(p.Kinds), true
+	case `issueCodes`:
+		return stringsOrUndef(p.IssueCodes), true
+	}
+	return nil, false
+}
+
+func (p *RetryPolicy) InitHash() px.OrderedMap {
+	return RetryPolicyMetaType.InstanceHash(p)
+}
+
+func (p *RetryPolicy) PType() px.Type {
+	return RetryPolicyMetaType
+}
+
+func (p *RetryPolicy) String() string {
+	return px.ToString(p)
+}
+
+func (p *RetryPolicy) ToString(bld io.Writer, format px.FormatContext, g px.RDetect) {
+	types.ObjectToString(p, format, bld, g)
+}
+
+func durationOrUndef(d time.Duration) px.Value {
+	if d == 0 {
+		return px.Undef
+	}
+	return types.WrapTimespan(d)
+}
+
+func floatOrUndef(f float64) px.Value {
+	if f == 0 {
+		return px.Undef
+	}
+	return types.WrapFloat(f)
+}
+
+func stringsOrUndef(ss []string) px.Value {
+	if len(ss) == 0 {
+		return px.Undef
+	}
+	return types.WrapStrings(ss)
+}
+
+func stringsFromList(v px.Value) []string {
+	l := v.(px.List)
+	ss := make([]string, l.Len())
+	l.EachWithIndex(func(e px.Value, i int) { ss[i] = e.String() })
+	return ss
+}
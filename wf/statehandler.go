@@ -2,6 +2,7 @@ package wf
 
 import (
 	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
 	"github.com/lyraproj/servicesdk/serviceapi"
 )
 
@@ -9,15 +10,30 @@ type StateHandler interface {
 	Step
 
 	Interface() interface{}
+
+	// HandlerFor returns the type of the state that is handled, or nil if the type is unknown
+	HandlerFor() px.Type
+}
+
+// WithHandlerFor assigns the type of the handled state to the given state handler and returns the state
+// handler.
+func WithHandlerFor(h StateHandler, stateType px.Type) StateHandler {
+	if hs, ok := h.(interface{ setHandlerFor(px.Type) }); ok {
+		hs.setHandlerFor(stateType)
+		return h
+	}
+	panic(px.Error(UnsupportedOption, issue.H{`step`: h, `option`: `handlerFor`}))
 }
 
 type stateHandler struct {
 	step
-	api interface{}
+	timed
+	api        interface{}
+	handlerFor px.Type
 }
 
 func MakeStateHandler(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter, api interface{}) StateHandler {
-	return &stateHandler{step{name, origin, when, parameters, returns}, api}
+	return &stateHandler{step{name, origin, when, parameters, returns, nil}, timed{}, api, nil}
 }
 
 func (a *stateHandler) Label() string {
@@ -27,3 +43,11 @@ func (a *stateHandler) Label() string {
 func (a *stateHandler) Interface() interface{} {
 	return a.api
 }
+
+func (a *stateHandler) HandlerFor() px.Type {
+	return a.handlerFor
+}
+
+func (a *stateHandler) setHandlerFor(stateType px.Type) {
+	a.handlerFor = stateType
+}
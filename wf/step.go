@@ -1,6 +1,8 @@
 package wf
 
 import (
+	"time"
+
 	"github.com/lyraproj/issue/issue"
 	"github.com/lyraproj/pcore/px"
 	"github.com/lyraproj/servicesdk/serviceapi"
@@ -27,12 +29,59 @@ type Step interface {
 	Returns() []serviceapi.Parameter
 }
 
+// A Retryable is a Step that can be retried when it fails. All steps created by this package are
+// Retryable.
+type Retryable interface {
+	Step
+
+	// Retry returns the policy that controls how the Step is retried when it fails, or nil if
+	// the Step should not be retried.
+	Retry() *RetryPolicy
+}
+
+// A Timed is a Step that limits the duration of what it invokes. Actions, resources, state handlers, and
+// waits are Timed. The steps that contain other steps are not since they invoke nothing themselves.
+type Timed interface {
+	Step
+
+	// Timeout returns the maximum duration of an invocation of the API or the state of the Step, or of
+	// the whole wait of a Wait, or zero if the duration is unlimited.
+	Timeout() time.Duration
+}
+
+// WithRetry assigns the given retry policy to the given step and returns the step.
+//
+// The With functions of this package are intended to be used when a step is created, before it is registered
+// with a service. They report an UnsupportedOption error when given a step that wasn't created by this package.
+func WithRetry(s Step, policy *RetryPolicy) Step {
+	if rs, ok := s.(interface{ setRetry(*RetryPolicy) }); ok {
+		rs.setRetry(policy)
+		return s
+	}
+	panic(px.Error(UnsupportedOption, issue.H{`step`: s, `option`: `retry`}))
+}
+
+// WithTimeout assigns the given timeout to the given Timed step and returns the step.
+func WithTimeout(s Step, timeout time.Duration) Step {
+	if ts, ok := s.(interface{ setTimeout(time.Duration) }); ok {
+		ts.setTimeout(timeout)
+		return s
+	}
+	panic(px.Error(UnsupportedOption, issue.H{`step`: s, `option`: `timeout`}))
+}
+
 type step struct {
 	name       string
 	origin     issue.Location
 	when       Condition
 	parameters []serviceapi.Parameter
 	returns    []serviceapi.Parameter
+	retry      *RetryPolicy
+}
+
+// timed is embedded by the steps that are Timed
+type timed struct {
+	timeout time.Duration
 }
 
 func (a *step) When() Condition {
@@ -55,5 +104,21 @@ func (a *step) Returns() []serviceapi.Parameter {
 	return a.returns
 }
 
+func (a *step) Retry() *RetryPolicy {
+	return a.retry
+}
+
+func (a *step) setRetry(policy *RetryPolicy) {
+	a.retry = policy
+}
+
+func (t *timed) Timeout() time.Duration {
+	return t.timeout
+}
+
+func (t *timed) setTimeout(timeout time.Duration) {
+	t.timeout = timeout
+}
+
 func (a *step) Resolve(px.Context) {
 }
@@ -0,0 +1,59 @@
+package wf_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+// foreignStep is a Step that is implemented outside of the wf package
+type foreignStep struct {
+	wf.Step
+}
+
+func ExampleWithRetry() {
+	pcore.Do(func(c px.Context) {
+		s := wf.NewAction(c, func(b wf.ActionBuilder) {
+			b.Name(`action`)
+			b.Doer(func() {})
+			b.Retry(&wf.RetryPolicy{MaxAttempts: 3})
+			b.Timeout(time.Second)
+		})
+		fmt.Println(s.(wf.Retryable).Retry().MaxAttempts, s.(wf.Timed).Timeout())
+
+		var fs wf.Step = foreignStep{s}
+		_, ok := fs.(wf.Retryable)
+		fmt.Println(ok)
+
+		for _, f := range []func(){
+			func() { wf.WithRetry(foreignStep{s}, &wf.RetryPolicy{MaxAttempts: 3}) },
+			func() { wf.WithTimeout(foreignStep{s}, time.Second) },
+			func() {
+				wf.NewCall(c, func(b wf.CallBuilder) {
+					b.Name(`call`)
+					b.CallTo(`My::Target`)
+					b.Timeout(time.Second)
+				})
+			},
+		} {
+			func() {
+				defer func() {
+					r := recover().(issue.Reported)
+					fmt.Println(r.Code(), r.Argument(`option`))
+				}()
+				f()
+			}()
+		}
+	})
+
+	// Output:
+	// 3 1s
+	// false
+	// WF_UNSUPPORTED_OPTION retry
+	// WF_UNSUPPORTED_OPTION timeout
+	// WF_UNSUPPORTED_OPTION timeout
+}
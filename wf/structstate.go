@@ -0,0 +1,99 @@
+package wf
+
+import (
+	"reflect"
+	"regexp"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/pcore/types"
+)
+
+// A ResolvableState is a State that resolves itself from the parameters of its resource and hence doesn't
+// need a StateConverter.
+type ResolvableState interface {
+	State
+
+	// Resolve resolves the state using the given parameters
+	Resolve(c px.Context, parameters px.OrderedMap) px.PuppetObject
+}
+
+// parameterReference matches $name and ${name} in a string, and also $$ which is the escape for a literal $
+var parameterReference = regexp.MustCompile(`\$(?:\$|\{([a-z]\w*)\}|([a-z]\w*))`)
+
+type structState struct {
+	typ   px.ObjectType
+	value reflect.Value
+}
+
+// MakeStructState returns a ResolvableState of the given type that is produced from the given go struct value
+// or pointer to a go struct value. A string in the state that consists of a single $name or ${name} is replaced
+// by the parameter with that name when the state is resolved. Such references that are embedded in a longer
+// string are replaced by the string form of the parameter. A $$ is replaced by a single $ so that a literal
+// $name can be written as $$name.
+func MakeStructState(typ px.ObjectType, value interface{}) ResolvableState {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr {
+		pv := reflect.New(rv.Type())
+		pv.Elem().Set(rv)
+		rv = pv
+	}
+	return &structState{typ, rv}
+}
+
+func (s *structState) Type() px.ObjectType {
+	return s.typ
+}
+
+func (s *structState) State() interface{} {
+	return s.value.Interface()
+}
+
+func (s *structState) Resolve(c px.Context, parameters px.OrderedMap) px.PuppetObject {
+	hash := s.typ.InstanceHash(px.WrapReflected(c, s.value).(px.PuppetObject))
+	return px.New(c, s.typ, interpolate(hash, parameters)).(px.PuppetObject)
+}
+
+// interpolate replaces all parameter references in strings found in the given value, or in the arrays and
+// hashes that it contains, with the value of the referenced parameter
+func interpolate(v px.Value, parameters px.OrderedMap) px.Value {
+	switch v := v.(type) {
+	case px.StringValue:
+		s := v.String()
+		ms := parameterReference.FindAllStringSubmatchIndex(s, -1)
+		if len(ms) == 0 {
+			return v
+		}
+		if len(ms) == 1 && ms[0][0] == 0 && ms[0][1] == len(s) {
+			return parameter(s, ms[0], parameters)
+		}
+		return types.WrapString(parameterReference.ReplaceAllStringFunc(s, func(ref string) string {
+			return parameter(ref, parameterReference.FindStringSubmatchIndex(ref), parameters).String()
+		}))
+	case *types.Array:
+		return v.Map(func(e px.Value) px.Value { return interpolate(e, parameters) })
+	case *types.Hash:
+		return v.MapEntries(func(e px.MapEntry) px.MapEntry {
+			return types.WrapHashEntry(e.Key(), interpolate(e.Value(), parameters))
+		})
+	}
+	return v
+}
+
+// parameter returns the value of the parameter that is referenced by the given submatch of the given string,
+// or a literal $ when the submatch is the $$ escape
+func parameter(s string, m []int, parameters px.OrderedMap) px.Value {
+	var n string
+	switch {
+	case m[2] >= 0:
+		n = s[m[2]:m[3]]
+	case m[4] >= 0:
+		n = s[m[4]:m[5]]
+	default:
+		return types.WrapString(`$`)
+	}
+	if v, ok := parameters.Get4(n); ok {
+		return v
+	}
+	panic(px.Error(px.UnknownVariable, issue.H{`name`: n}))
+}
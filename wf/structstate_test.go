@@ -0,0 +1,68 @@
+package wf_test
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+type Server struct {
+	Name    string
+	Url     string
+	Price   string
+	Aliases []string
+	Labels  map[string]interface{}
+}
+
+func serverResource(c px.Context, state *Server) wf.ResolvableState {
+	return wf.NewResource(c, func(b wf.ResourceBuilder) {
+		b.Name(`server`)
+		b.StateStruct(state)
+	}).State().(wf.ResolvableState)
+}
+
+func ExampleMakeStructState() {
+	pcore.Do(func(c px.Context) {
+		px.AddTypes(c, c.Reflector().TypeFromReflect(`Test::Server`, nil, reflect.TypeOf(&Server{})))
+		s := serverResource(c, &Server{
+			Name:    `$name`,
+			Url:     `http://${name}:$port/$$name`,
+			Price:   `$$5`,
+			Aliases: []string{`$name`, `www.$name`},
+			Labels:  map[string]interface{}{`port`: `$port`, `tags`: []interface{}{`$name`}}})
+
+		fmt.Println(s.Type().Name())
+		fmt.Println(s.Resolve(c, px.Wrap(c, map[string]interface{}{`name`: `example.com`, `port`: 8080}).(px.OrderedMap)))
+	})
+
+	// Output:
+	// Test::Server
+	// Test::Server('name' => 'example.com', 'url' => 'http://example.com:8080/$name', 'price' => '$5', 'aliases' => ['example.com', 'www.example.com'], 'labels' => {'port' => 8080, 'tags' => ['example.com']})
+}
+
+func ExampleMakeStructState_errors() {
+	pcore.Do(func(c px.Context) {
+		for _, f := range []func(){
+			func() {
+				serverResource(c, &Server{Name: `$name`})
+			},
+			func() {
+				px.AddTypes(c, c.Reflector().TypeFromReflect(`Test::Server`, nil, reflect.TypeOf(&Server{})))
+				serverResource(c, &Server{Name: `${name}`, Url: `http://$host`}).Resolve(c, px.SingletonMap(`name`, px.Wrap(c, `x`)))
+			},
+		} {
+			func() {
+				defer func() { fmt.Println(recover().(issue.Reported).Code()) }()
+				f()
+			}()
+		}
+	})
+
+	// Output:
+	// WF_UNREGISTERED_STATE_TYPE
+	// PCORE_UNKNOWN_VARIABLE
+}
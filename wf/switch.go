@@ -0,0 +1,91 @@
+package wf
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/serviceapi"
+)
+
+// A Case is a step of a Switch together with the condition that selects it
+type Case struct {
+	Condition Condition
+	Step      Step
+}
+
+// A Switch executes the step of its first Case with a condition that is true, or its default step
+// when no such case exists. All cases and the default share the returns of the switch.
+type Switch interface {
+	Step
+
+	// Cases returns the cases of the switch in the order that they are evaluated
+	Cases() []Case
+
+	// Default returns the step that is executed when no case is selected, or nil when the switch
+	// has no default
+	Default() Step
+}
+
+type switchStep struct {
+	step
+	cases       []Case
+	defaultStep Step
+}
+
+// MakeSwitch creates a Switch. The returns of the switch default to the returns of its first case. An error
+// is raised when a case or the default has returns that differ from the returns of the switch.
+func MakeSwitch(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter, cases []Case, defaultStep Step) Switch {
+	if len(returns) == 0 && len(cases) > 0 {
+		returns = cases[0].Step.Returns()
+	}
+	s := &switchStep{step{name, origin, when, parameters, returns, nil}, cases, defaultStep}
+	expected := returnNames(returns)
+	check := func(cs Step) {
+		if actual := returnNames(cs.Returns()); actual != expected {
+			panic(issue.NewReported(SwitchReturnsMismatch, issue.SeverityError,
+				issue.H{`step`: cs, `returns`: actual, `switch`: s, `expected`: expected}, cs.Origin()))
+		}
+	}
+	for _, c := range cases {
+		check(c.Step)
+	}
+	if defaultStep != nil {
+		check(defaultStep)
+	}
+	return s
+}
+
+func returnNames(returns []serviceapi.Parameter) string {
+	names := make([]string, len(returns))
+	for i, r := range returns {
+		names[i] = r.Name()
+	}
+	sort.Strings(names)
+	return `[` + strings.Join(names, `, `) + `]`
+}
+
+func (s *switchStep) Label() string {
+	return `switch ` + s.name
+}
+
+func (s *switchStep) Cases() []Case {
+	return s.cases
+}
+
+func (s *switchStep) Default() Step {
+	return s.defaultStep
+}
+
+// Select returns the step of the first case of the given switch with a condition that is true for the given
+// parameters, or the default step of the switch when no such case exists. The returned step is nil when no
+// case is selected and the switch has no default.
+func Select(s Switch, parameters px.OrderedMap) Step {
+	for _, c := range s.Cases() {
+		if c.Condition.IsTrue(parameters) {
+			return c.Step
+		}
+	}
+	return s.Default()
+}
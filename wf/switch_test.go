@@ -0,0 +1,108 @@
+package wf_test
+
+import (
+	"fmt"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+func call(b wf.ChildBuilder, name string) {
+	b.Call(func(cb wf.CallBuilder) {
+		cb.Name(name)
+		cb.CallTo(`My::Target`)
+	})
+}
+
+func ExampleNewSwitch() {
+	pcore.Do(func(c px.Context) {
+		s := wf.NewSwitch(c, func(b wf.SwitchBuilder) {
+			b.Name(`sw`)
+			b.Case(`prod`)
+			call(b, `strict`)
+			call(b, `lenient`)
+			b.Case(`test`)
+			call(b, `quick`)
+		})
+		for _, cs := range s.Cases() {
+			fmt.Println(cs.Condition, cs.Step.Name())
+		}
+		fmt.Println(`default`, s.Default().Name())
+	})
+
+	// Output:
+	// prod sw::strict
+	// test sw::quick
+	// default sw::lenient
+}
+
+func ExampleNewSwitch_errors() {
+	pcore.Do(func(c px.Context) {
+		for _, bf := range []func(b wf.SwitchBuilder){
+			func(b wf.SwitchBuilder) {
+				b.Case(`prod`)
+				call(b, `strict`)
+				call(b, `lenient`)
+				call(b, `sloppy`)
+			},
+			func(b wf.SwitchBuilder) {
+				b.Case(`prod`)
+				b.Case(`test`)
+				call(b, `quick`)
+			},
+			func(b wf.SwitchBuilder) {
+				b.Case(`prod`)
+				call(b, `strict`)
+				b.Case(`test`)
+			},
+			func(b wf.SwitchBuilder) {
+				call(b, `lenient`)
+			},
+		} {
+			func() {
+				defer func() { fmt.Println(recover().(issue.Reported).Code()) }()
+				wf.NewSwitch(c, func(b wf.SwitchBuilder) {
+					b.Name(`sw`)
+					bf(b)
+				})
+			}()
+		}
+	})
+
+	// Output:
+	// WF_SWITCH_DUPLICATE_DEFAULT
+	// WF_SWITCH_CASE_WITHOUT_STEP
+	// WF_SWITCH_CASE_WITHOUT_STEP
+	// WF_SWITCH_NO_CASES
+}
+
+func ExampleMakeSwitch() {
+	pcore.Do(func(c px.Context) {
+		s := wf.MakeSwitch(`test::s`, nil, wf.Always, nil, nil, []wf.Case{
+			{Condition: wf.Parse(`env == 'prod'`), Step: action(`s::strict`, 1, params(`host`), params(`url`))},
+			{Condition: wf.Parse(`env == 'test'`), Step: action(`s::quick`, 2, nil, params(`url`))},
+		}, nil)
+		fmt.Println(names(s.Returns()))
+		for _, env := range []string{`prod`, `test`, `dev`} {
+			if st := wf.Select(s, px.SingletonMap(`env`, px.Wrap(c, env))); st != nil {
+				fmt.Println(env, st.Name())
+			} else {
+				fmt.Println(env, `none`)
+			}
+		}
+
+		defer func() { fmt.Println(recover().(issue.Reported).Code()) }()
+		wf.MakeSwitch(`test::s`, nil, wf.Always, nil, nil, []wf.Case{
+			{Condition: wf.Parse(`env == 'prod'`), Step: action(`s::strict`, 1, nil, params(`url`))},
+		}, action(`s::lenient`, 2, nil, params(`uri`)))
+	})
+
+	// Output:
+	// [url]
+	// prod test::s::strict
+	// test test::s::quick
+	// dev none
+	// WF_SWITCH_RETURNS_MISMATCH
+}
@@ -0,0 +1,72 @@
+package wf
+
+import (
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/servicesdk/serviceapi"
+)
+
+// A Wait repeatedly reads the state of a resource, or invokes a method of an API, until a condition over
+// the result holds. The returns of the wait are produced from the last result. The Timeout of a Wait is
+// the maximum duration of the wait as a whole.
+type Wait interface {
+	Step
+
+	// Resource returns the name of the resource that is read using the handler for its state type, or an
+	// empty string when an API method is invoked
+	Resource() string
+
+	// API returns the identifier of the API that is invoked when no resource is given
+	API() string
+
+	// Method returns the name of the API method that is invoked with the parameters of the wait
+	Method() string
+
+	// Until returns the condition that must hold for the state that is read or the value that is returned
+	// by the API method
+	Until() Condition
+
+	// Interval returns the delay between two attempts
+	Interval() time.Duration
+}
+
+type wait struct {
+	step
+	timed
+	resource string
+	api      string
+	method   string
+	until    Condition
+	interval time.Duration
+}
+
+// MakeWait creates a Wait. Either resource or api and method must be given.
+func MakeWait(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter,
+	resource, api, method string, until Condition, interval time.Duration) Wait {
+	return &wait{step{name, origin, when, parameters, returns, nil}, timed{}, resource, api, method, until, interval}
+}
+
+func (w *wait) Label() string {
+	return `wait ` + w.name
+}
+
+func (w *wait) Resource() string {
+	return w.resource
+}
+
+func (w *wait) API() string {
+	return w.api
+}
+
+func (w *wait) Method() string {
+	return w.method
+}
+
+func (w *wait) Until() Condition {
+	return w.until
+}
+
+func (w *wait) Interval() time.Duration {
+	return w.interval
+}
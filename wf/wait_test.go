@@ -0,0 +1,55 @@
+package wf_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lyraproj/issue/issue"
+	"github.com/lyraproj/pcore/pcore"
+	"github.com/lyraproj/pcore/px"
+	"github.com/lyraproj/servicesdk/wf"
+)
+
+func ExampleMakeWait() {
+	pcore.Do(func(c px.Context) {
+		for _, w := range []wf.Wait{
+			wf.MakeWait(`test::ready`, nil, wf.Always, nil, params(`status`), `test::db`, ``, ``,
+				wf.Parse(`status == 'available'`), 5*time.Second),
+			wf.MakeWait(`test::healthy`, nil, wf.Always, params(`url`), nil, ``, `My::Probe`, `check`,
+				wf.Parse(`healthy`), 0),
+		} {
+			fmt.Println(w.Label())
+			fmt.Printf("%q %q %q %s %s\n", w.Resource(), w.API(), w.Method(), w.Until(), w.Interval())
+		}
+	})
+
+	// Output:
+	// wait test::ready
+	// "test::db" "" "" status == 'available' 5s
+	// wait test::healthy
+	// "" "My::Probe" "check" healthy 0s
+}
+
+func ExampleNewWait_errors() {
+	pcore.Do(func(c px.Context) {
+		for _, bf := range []func(b wf.WaitBuilder){
+			func(b wf.WaitBuilder) { b.Until(`ready`) },
+			func(b wf.WaitBuilder) { b.Resource(`db`) },
+		} {
+			func() {
+				defer func() {
+					r := recover().(issue.Reported)
+					fmt.Println(r.Code(), r.Argument(`field`))
+				}()
+				wf.NewWait(c, func(b wf.WaitBuilder) {
+					b.Name(`w`)
+					bf(b)
+				})
+			}()
+		}
+	})
+
+	// Output:
+	// WF_MISSING_REQUIRED_FIELD resource
+	// WF_MISSING_REQUIRED_FIELD until
+}
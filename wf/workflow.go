@@ -17,7 +17,7 @@ type workflow struct {
 }
 
 func MakeWorkflow(name string, origin issue.Location, when Condition, parameters, returns []serviceapi.Parameter, steps []Step) Workflow {
-	return &workflow{step{name, origin, when, parameters, returns}, steps}
+	return &workflow{step{name, origin, when, parameters, returns, nil}, steps}
 }
 
 func (w *workflow) Label() string {